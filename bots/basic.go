@@ -9,23 +9,50 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+func init() {
+	Register(BotEntry{
+		Name:        "BasicBot",
+		Description: "Wins when it can, blocks the enemy and otherwise ranks moves with weights",
+		Options: []BotOption{
+			{Name: "weights", Type: "string", Description: "Weights file from the tuner, defaults to the built-in weights"},
+		},
+		New: func(opts BotOptions) (santorini.BotInitializer, error) {
+			if opts.String("weights") == "" {
+				return NewBasicBot, nil
+			}
+			weights, err := LoadBasicWeights(opts.String("weights"))
+			if err != nil {
+				return nil, err
+			}
+			return NewWeightedBasicBot(weights), nil
+		},
+	})
+}
+
 /* BasicBot is a bot that will perform the following actions:
  *
  * 1. If the bot can win, do it
- * 2. If the enemy can win, and we can block it, then do that
- * 3. Random
+ * 2. If the bot can force a win by its next turn, with a double threat, an unblockable climb
+ *    or by trapping the enemy, do it
+ * 3. If the enemy can win, and we can block it, then do that
+ * 4. Rank the turns that do not let the enemy force a win, and play the best one
  */
 type BasicBot struct {
 	Board        *santorini.Board
 	Workers      map[int]santorini.Tile
 	EnemyWorkers []santorini.Tile
 	Team         int
+	Weights      BasicWeights
 
 	logger *logrus.Logger
 	turns  []santorini.Turn // Turns for the round, by worker
 
 	chosenWorker  int // the worker we recommend moving
 	turnsByWorker map[int][]santorini.Turn
+
+	// Why the last turn was chosen and the turns it was chosen from, for Explain
+	reason     string
+	considered []santorini.Turn
 }
 
 func (bb *BasicBot) Name() string {
@@ -41,12 +68,24 @@ func (bb *BasicBot) log(fmtstr string, args ...interface{}) {
 }
 
 func NewBasicBot(team int, board *santorini.Board, logger *logrus.Logger) santorini.TurnSelector {
+	return newBasicBot(team, board, logger, DefaultBasicWeights)
+}
+
+// NewWeightedBasicBot returns an initializer for a BasicBot that ranks moves with the given weights
+func NewWeightedBasicBot(weights BasicWeights) santorini.BotInitializer {
+	return func(team int, board *santorini.Board, logger *logrus.Logger) santorini.TurnSelector {
+		return newBasicBot(team, board, logger, weights)
+	}
+}
+
+func newBasicBot(team int, board *santorini.Board, logger *logrus.Logger, weights BasicWeights) *BasicBot {
 	// Figure out where my workers are, and figure out where the enemy workers are
 	ai := &BasicBot{
 		Board:        board,
 		Workers:      make(map[int]santorini.Tile, 2),
 		EnemyWorkers: make([]santorini.Tile, 0, 2),
 		Team:         team,
+		Weights:      weights,
 
 		logger:        logger,
 		turnsByWorker: make(map[int][]santorini.Turn),
@@ -83,72 +122,120 @@ func (bb *BasicBot) update() {
 
 func (bb *BasicBot) SelectTurn() *santorini.Turn {
 	bb.update()
+	if len(bb.turns) == 0 {
+		// Trapped, the game is lost
+		bb.reason, bb.considered = "", nil
+		return nil
+	}
 	if winningMoves := GetWinningMoves(bb.turns); len(winningMoves) > 0 {
 		bb.log("Detected a winning move. Executing it")
+		bb.reason, bb.considered = "winning move", winningMoves[:1]
 		return &winningMoves[0]
 	}
 
+	if forcing := bb.forcingTurns(); len(forcing) > 0 {
+		bb.log("Detected a forced win. Executing it")
+		bb.turns = forcing
+		bb.sortMoves()
+		bb.reason, bb.considered = "forcing a win", bb.turns
+		return &bb.turns[len(bb.turns)-1]
+	}
+
 	// If we need to defend, do it
 	if t := bb.defend(); t != nil {
 		return t
 	}
 
 	// if a worker is almost trapped, get them out
-	if t := bb.escapeTraps(); t != nil {
-		bb.chosenWorker = t.Worker
-		bb.rankMove(*t)
+	bb.reason = "ranked"
+	escaping := bb.escapeTraps()
+	if escaping != nil {
+		bb.chosenWorker = escaping.Worker
 	}
 
 	bb.sortMoves()
-	/* Debug, print top ten moves and weights
-	for x, i := range bb.turns {
-		if x > len(bb.turns)-10 {
-			fmt.Printf("%d %+v\n", bb.rankMove(i), i)
-		}
+	if safe := bb.bestSafe(bb.turns); len(safe) < len(bb.turns) {
+		bb.log("Avoiding %d turns that let the enemy force a win", len(bb.turns)-len(safe))
+		bb.turns = safe
+		bb.reason = "ranked, avoiding a forced loss"
 	}
-	*/
+	if escaping != nil {
+		bb.reason += fmt.Sprintf(", worker %d is escaping a trap", escaping.Worker)
+	}
+	bb.considered = bb.turns
 	return &bb.turns[len(bb.turns)-1] // use the last move (Highest ranked)
 }
 
+// Explain ranks the turns the last turn was chosen from, with the weights that scored each of them
+func (bb *BasicBot) Explain(candidates int) *santorini.Explanation {
+	if bb.considered == nil {
+		return nil
+	}
+	explanation := &santorini.Explanation{Reason: bb.reason}
+	// The chosen turn is always last
+	for i := len(bb.considered) - 1; i >= 0 && len(explanation.Candidates) < candidates; i-- {
+		features := make(map[string]float64)
+		score := bb.rank(bb.considered[i], features)
+		explanation.Candidates = append(explanation.Candidates, santorini.Candidate{
+			Turn:     bb.considered[i],
+			Score:    float64(score),
+			Features: features,
+		})
+	}
+	return explanation
+}
+
 func (bb *BasicBot) rankMove(turn santorini.Turn) int {
+	return bb.rank(turn, nil)
+}
+
+// rank scores a turn, adding what each weight contributed to features unless it is nil
+func (bb *BasicBot) rank(turn santorini.Turn, features map[string]float64) int {
 	rank := 0
+	add := func(feature string, value int) {
+		rank += value
+		if features != nil && value != 0 {
+			features[feature] += float64(value)
+		}
+	}
+	w := bb.Weights
 
 	worker := bb.Workers[turn.Worker]
 	// if the worker is moving up/down, add/remove points (going up good)
 	if diff := turn.MoveTo.GetHeight() - worker.GetHeight(); diff > 0 {
-		rank += 50 // move up
+		add("move_up", w.MoveUp)
 	} else if diff == -2 {
-		rank -= 100
+		add("move_down_two", w.MoveDownTwo)
 	} else if diff == -1 {
-		rank -= 20
+		add("move_down_one", w.MoveDownOne)
 	}
 
 	// dislike corners and edges
-	rank -= (8 - len(bb.Board.GetSurroundingTiles(turn.Build.GetX(), turn.Build.GetY()))) * 5
+	add("edge_build", (8-len(bb.Board.GetSurroundingTiles(turn.Build.GetX(), turn.Build.GetY())))*w.EdgeBuild)
 	// dont like moving to corner
 	if len(bb.Board.GetSurroundingTiles(turn.MoveTo.GetX(), turn.MoveTo.GetY())) == 3 {
-		rank -= 20
+		add("corner_move", w.CornerMove)
 	}
 
 	// if the move will limit us in the future, subtract a point
 	if len(bb.Board.GetMoveableTiles(turn.MoveTo)) < 2 {
-		rank -= 10
+		add("few_moves", w.FewMoves)
 	}
 	if len(bb.Board.GetBuildableTiles(bb.Team, -1, turn.MoveTo)) < 2 {
-		rank -= 10
+		add("few_builds", w.FewBuilds)
 	}
 
 	// Dont build 2 up (unless capping, which is already handled)
 	if turn.Build.GetHeight() > turn.MoveTo.GetHeight() {
-		rank -= 30
+		add("build_above", w.BuildAbove)
 	} else if turn.Build.GetHeight()+1 == 3 {
 		// If the build is increasing the height to 3, super rank it
-		rank += 30
+		add("build_three", w.BuildThree)
 	} else if turn.Build.GetHeight()+1 > turn.MoveTo.GetHeight() {
 		// Building up next to ourselves is good (as oposed to starting on the ground)
-		rank += 20
+		add("build_up", w.BuildUp)
 	} else if turn.Build.GetHeight() > 0 {
-		rank += 30
+		add("build_on_block", w.BuildOnBlock)
 	}
 
 	surroundingBuild := bb.Board.GetSurroundingTiles(turn.Build.GetX(), turn.Build.GetY())
@@ -156,12 +243,12 @@ func (bb *BasicBot) rankMove(turn santorini.Turn) int {
 	for _, tile := range surroundingBuild {
 		if tile.IsOccupied() && tile.GetTeam() != bb.Team {
 			if turn.Build.GetHeight() == 2 {
-				rank -= 111111111
+				add("enemy_can_climb", -111111111)
 			}
-			rank -= 10
+			add("build_near_enemy", w.BuildNearEnemy)
 		}
 		if tile.GetHeight() > 0 {
-			rank += 3
+			add("build_near_block", w.BuildNearBlock)
 		}
 	}
 
@@ -169,24 +256,92 @@ func (bb *BasicBot) rankMove(turn santorini.Turn) int {
 	for _, tile := range bb.Board.GetSurroundingTiles(turn.MoveTo.GetX(), turn.MoveTo.GetY()) {
 		// Try not to move next to my buddy
 		if tile.GetTeam() == bb.Team {
-			rank -= 30
+			add("near_teammate", w.NearTeammate)
 		}
 	}
 	if turn.Build.GetHeight() == 2 && turn.MoveTo.GetHeight() == 2 {
-		rank += 10
+		add("stay_high", w.StayHigh)
+	}
+
+	// Keep both workers able to move, and dome the enemy in when we can
+	after := play(bb.Board, turn)
+	for _, worker := range after.Tiles {
+		if !worker.IsOccupied() {
+			continue
+		}
+		moves := len(after.GetMoveableTiles(worker))
+		if worker.GetTeam() != bb.Team {
+			if moves == 0 && len(bb.Board.GetMoveableTiles(bb.Board.GetTile(worker.GetX(), worker.GetY()))) > 0 {
+				add("trap_enemy", w.TrapEnemy)
+			}
+		} else if moves == 0 {
+			add("trapped_worker", w.TrappedWorker)
+		} else if moves == 1 {
+			add("cramped_worker", w.CrampedWorker)
+		}
 	}
 
 	// use the recommended worker
 	if turn.Worker == bb.chosenWorker {
-		rank += 100000
+		add("chosen_worker", 100000)
 	}
 	return rank
 }
 
 func (bb *BasicBot) sortMoves() {
-	sort.Slice(bb.turns, func(i, j int) bool {
-		return bb.rankMove(bb.turns[i]) < bb.rankMove(bb.turns[j])
+	sortTurns(bb.turns, bb.rankMove)
+}
+
+// sortTurns sorts the turns from lowest to highest rank, ranking each turn once
+func sortTurns(turns []santorini.Turn, rank func(santorini.Turn) int) {
+	ranked := make([]struct {
+		turn santorini.Turn
+		rank int
+	}, len(turns))
+	for i, turn := range turns {
+		ranked[i].turn, ranked[i].rank = turn, rank(turn)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		return ranked[i].rank < ranked[j].rank
 	})
+	for i := range ranked {
+		turns[i] = ranked[i].turn
+	}
+}
+
+// forcingTurns returns the turns that win the game by our next turn whatever the enemies do
+func (bb *BasicBot) forcingTurns() []santorini.Turn {
+	enemies := enemies(bb.Board, bb.Team)
+	var forcing []santorini.Turn
+	for _, turn := range bb.turns {
+		if forcesWin(play(bb.Board, turn), bb.Team, enemies) {
+			forcing = append(forcing, turn)
+		}
+	}
+	return forcing
+}
+
+// bestSafe takes turns sorted from lowest to highest rank and drops the highest ranked turns that
+// let an enemy force a win, so the best safe turn is last. Looking ahead from every turn is costly,
+// so the turns are checked from the highest rank down and the ones ranked below the first safe turn
+// are kept without checking. Every turn is kept if every turn loses
+func (bb *BasicBot) bestSafe(turns []santorini.Turn) []santorini.Turn {
+	for i := len(turns) - 1; i >= 0; i-- {
+		if !bb.allowsForcedWin(play(bb.Board, turns[i])) {
+			return turns[:i+1]
+		}
+	}
+	return turns
+}
+
+// allowsForcedWin returns true if any enemy can force a win from the board after our turn
+func (bb *BasicBot) allowsForcedWin(board *santorini.Board) bool {
+	for _, enemy := range enemies(board, bb.Team) {
+		if canForceWin(board, enemy, enemies(board, enemy)) {
+			return true
+		}
+	}
+	return false
 }
 
 // Check if a winning move exists in any of the possible moves
@@ -201,33 +356,39 @@ func GetWinningMoves(turns []santorini.Turn) []santorini.Turn {
 	return res
 }
 
-// defend tries to stop the enemy
+// defend tries to stop the enemy. The enemy that moves next is the most dangerous, so when
+// several can win, the first one in turn order that can be blocked is
 func (bb *BasicBot) defend() *santorini.Turn {
 	// See if the enemy can win, if they can, then try to block them
 	defendMoves := make([]santorini.Turn, 0, 10) // Moves that we can make to defend ourselves
 
-	enemyWinningMoves := GetWinningMoves(bb.Board.GetValidTurns(bb.EnemyWorkers[0].GetTeam()))
+	for _, enemy := range enemies(bb.Board, bb.Team) {
+		enemyWinningMoves := GetWinningMoves(bb.Board.GetValidTurns(enemy))
 
-	// Try to block the enemy winning moves
-	for _, et := range enemyWinningMoves {
-		for _, myturn := range bb.turns {
-			//if I can build where the enemy will go, then do it
-			if myturn.Build.GetX() == et.MoveTo.GetX() && myturn.Build.GetY() == et.MoveTo.GetY() {
-				defendMoves = append(defendMoves, myturn)
+		// Try to block the enemy winning moves
+		for _, et := range enemyWinningMoves {
+			for _, myturn := range bb.turns {
+				//if I can build where the enemy will go, then do it
+				if myturn.Build.GetX() == et.MoveTo.GetX() && myturn.Build.GetY() == et.MoveTo.GetY() {
+					defendMoves = append(defendMoves, myturn)
+				}
 			}
 		}
-	}
 
-	if len(enemyWinningMoves) > len(defendMoves) {
-		bb.log("Enemy has more winning moves than I can block")
+		if len(enemyWinningMoves) > len(defendMoves) {
+			bb.log("Team %d has more winning moves than I can block", enemy)
+		}
+		if len(defendMoves) > 0 {
+			break
+		}
 	}
 	// if we need to defend ourselves, do it
 	// TODO: order the defend moves based on how good the move is
 	if len(defendMoves) > 0 {
 		bb.log("Capping enemy for defense")
-		sort.Slice(defendMoves, func(i, j int) bool {
-			return bb.rankMove(defendMoves[i]) < bb.rankMove(defendMoves[j])
-		})
+		sortTurns(defendMoves, bb.rankMove)
+		defendMoves = bb.bestSafe(defendMoves)
+		bb.reason, bb.considered = "blocking an enemy win", defendMoves
 		return &defendMoves[len(defendMoves)-1] // use the last move (Highest ranked)
 	}
 
@@ -237,7 +398,12 @@ func (bb *BasicBot) defend() *santorini.Turn {
 
 // If a worker is close to being trapped, have it escape
 func (bb *BasicBot) escapeTraps() *santorini.Turn {
-	for _, tile := range bb.Workers {
+	// Check the workers in order so the bot stays deterministic
+	for worker := 1; worker <= len(bb.Workers); worker++ {
+		tile, ok := bb.Workers[worker]
+		if !ok {
+			continue
+		}
 		if len(bb.Board.GetMoveableTiles(tile)) == 1 {
 			bb.log("Worker %d is trapped, escaping", tile.GetWorker())
 			if len(bb.turnsByWorker[tile.GetWorker()]) > 0 {
@@ -0,0 +1,294 @@
+package bots
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	santorini "santorini/pkg"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	Register(BotEntry{
+		Name:        "BookBot",
+		Description: "Plays from an opening book, then lets another bot take over",
+		Options: []BotOption{
+			{Name: "book", Type: "string", Description: "Opening book file"},
+			{Name: "bot", Type: "string", Default: "BasicBot", Description: "Bot to play once out of book"},
+		},
+		New: func(opts BotOptions) (santorini.BotInitializer, error) {
+			if opts.String("book") == "" {
+				return nil, errors.New("a book is required")
+			}
+			book, err := LoadBook(opts.String("book"))
+			if err != nil {
+				return nil, err
+			}
+			bot, err := Build(opts.String("bot"))
+			if err != nil {
+				return nil, err
+			}
+			return NewBookBot(book, bot), nil
+		},
+	})
+}
+
+// Book is an opening book. Positions are stored in their canonical form so every
+// rotation and reflection of a position shares the same entry.
+//
+// Books are saved as text, one candidate turn per line:
+//
+//	<position notation>	<turn notation>	<weight>
+//
+// with the turn written for the canonical position. Lines starting with # are ignored
+type Book struct {
+	Entries map[string][]BookEntry
+}
+
+// BookEntry is a candidate turn for a position. Turns with higher weights are played more often
+type BookEntry struct {
+	Turn   string
+	Weight int
+}
+
+// BookMove is a book turn translated back to the board it was looked up for
+type BookMove struct {
+	Turn   santorini.Turn
+	Weight int
+}
+
+func NewBook() *Book {
+	return &Book{
+		Entries: make(map[string][]BookEntry),
+	}
+}
+
+// LoadBook reads a book from a file
+func LoadBook(path string) (*Book, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	book := NewBook()
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected 3 tab separated fields", path, line)
+		}
+		weight, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid weight %q", path, line, fields[2])
+		}
+		book.Entries[fields[0]] = append(book.Entries[fields[0]], BookEntry{Turn: fields[1], Weight: weight})
+	}
+	return book, scanner.Err()
+}
+
+// Save writes the book to a file, sorted so books can be compared
+func (b *Book) Save(path string) error {
+	positions := make([]string, 0, len(b.Entries))
+	for position := range b.Entries {
+		positions = append(positions, position)
+	}
+	sort.Strings(positions)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, position := range positions {
+		for _, entry := range b.Entries[position] {
+			fmt.Fprintf(w, "%s\t%s\t%d\n", position, entry.Turn, entry.Weight)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Add increases the weight of a turn in a position, adding it if needed
+func (b *Book) Add(board *santorini.Board, turn santorini.Turn, weight int) {
+	position, sym := board.Canonical(turn.Team)
+	notation := sym.Turn(board.Size, turn).Notation()
+	entries := b.Entries[position]
+	for i := range entries {
+		if entries[i].Turn == notation {
+			entries[i].Weight += weight
+			return
+		}
+	}
+	b.Entries[position] = append(entries, BookEntry{Turn: notation, Weight: weight})
+}
+
+// AddGame adds the turns the victor took during the first plies of a game
+func (b *Book) AddGame(start *santorini.Board, moves []santorini.Turn, victor, plies int) {
+	board := start.Clone()
+	for i, turn := range moves {
+		if i >= plies {
+			return
+		}
+		if turn.Team == victor {
+			b.Add(board, turn, 1)
+		}
+		board.PlayTurn(turn)
+	}
+}
+
+// Lookup returns the book turns for the team in the current position
+func (b *Book) Lookup(board *santorini.Board, team int) []BookMove {
+	position, sym := board.Canonical(team)
+	entries := b.Entries[position]
+	if len(entries) == 0 {
+		return nil
+	}
+
+	canonical := board.Transform(sym)
+	inverse := sym.Inverse()
+	candidates := board.GetValidTurns(team)
+	moves := make([]BookMove, 0, len(entries))
+	for _, entry := range entries {
+		turn, err := canonical.ParseTurn(entry.Turn)
+		if err != nil || entry.Weight <= 0 {
+			continue
+		}
+		notation := inverse.Turn(board.Size, turn).Notation()
+		// Only return legal turns, using the tiles from the real board
+		for _, candidate := range candidates {
+			if candidate.Notation() == notation {
+				moves = append(moves, BookMove{Turn: candidate, Weight: entry.Weight})
+				break
+			}
+		}
+	}
+	return moves
+}
+
+// BookBot plays turns from an opening book while it can, then lets another bot take over
+type BookBot struct {
+	Book  *Book
+	Bot   santorini.TurnSelector
+	Team  int
+	Board *santorini.Board
+
+	rng *rand.Rand
+	// The book turns the last turn was picked from, nil if the bot played it
+	picked []BookMove
+}
+
+// NewBookBot returns an initializer for a bot that plays from the book before falling back to bot
+func NewBookBot(book *Book, bot santorini.BotInitializer) santorini.BotInitializer {
+	return func(team int, board *santorini.Board, logger *logrus.Logger) santorini.TurnSelector {
+		return &BookBot{
+			Book:  book,
+			Bot:   bot(team, board, logger),
+			Team:  team,
+			Board: board,
+			rng:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		}
+	}
+}
+
+func (b *BookBot) Name() string {
+	return b.Bot.Name() + "+Book"
+}
+
+// IsDeterministic is false since book turns are picked at random by weight
+func (b *BookBot) IsDeterministic() bool {
+	return false
+}
+
+// Seed the choice of book turns, and the bot it falls back to
+func (b *BookBot) Seed(seed int64) {
+	b.rng.Seed(seed)
+	if bot, ok := b.Bot.(santorini.Seedable); ok {
+		bot.Seed(b.rng.Int63())
+	}
+}
+
+func (b *BookBot) SelectTurn() *santorini.Turn {
+	moves := b.Book.Lookup(b.Board, b.Team)
+	total := 0
+	for _, move := range moves {
+		total += move.Weight
+	}
+	b.picked = nil
+	if total == 0 {
+		return b.Bot.SelectTurn()
+	}
+	b.picked = moves
+
+	pick := b.rng.Intn(total)
+	for i := range moves {
+		if pick < moves[i].Weight {
+			return &moves[i].Turn
+		}
+		pick -= moves[i].Weight
+	}
+	return b.Bot.SelectTurn()
+}
+
+// Explain lists the book turns by weight, or asks the bot when it played out of book
+func (b *BookBot) Explain(candidates int) *santorini.Explanation {
+	if b.picked == nil {
+		if explainer, ok := b.Bot.(santorini.Explainer); ok {
+			return explainer.Explain(candidates)
+		}
+		return nil
+	}
+	moves := make([]BookMove, len(b.picked))
+	copy(moves, b.picked)
+	sort.SliceStable(moves, func(i, j int) bool {
+		return moves[i].Weight > moves[j].Weight
+	})
+	explanation := &santorini.Explanation{Reason: "book move"}
+	for i := 0; i < len(moves) && i < candidates; i++ {
+		explanation.Candidates = append(explanation.Candidates, santorini.Candidate{Turn: moves[i].Turn, Score: float64(moves[i].Weight)})
+	}
+	return explanation
+}
+
+// Ponder with the bot the book falls back to
+func (b *BookBot) Ponder(board *santorini.Board, team int) {
+	if bot, ok := b.Bot.(santorini.Ponderer); ok {
+		bot.Ponder(board, team)
+	}
+}
+
+func (b *BookBot) StopPondering() {
+	if bot, ok := b.Bot.(santorini.Ponderer); ok {
+		bot.StopPondering()
+	}
+}
+
+// Interrupt the bot the book falls back to
+func (b *BookBot) Interrupt() {
+	if bot, ok := b.Bot.(santorini.Interrupter); ok {
+		bot.Interrupt()
+	}
+}
+
+// Close the bot the book falls back to
+func (b *BookBot) Close() error {
+	if closer, ok := b.Bot.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package bots
+
+import (
+	"path/filepath"
+	santorini "santorini/pkg"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookSymmetry(t *testing.T) {
+	board := santorini.DefaultPosition(2)
+	turn := santorini.Turn{Team: 1, Worker: 1, MoveTo: board.GetTile(1, 1), Build: board.GetTile(0, 0)}
+
+	book := NewBook()
+	book.Add(board, turn, 3)
+
+	// The default position is symmetric, so the mirrored turn is found as well
+	mirrored := santorini.Symmetry(1).Turn(board.Size, turn)
+	moves := book.Lookup(board, 1)
+	assert.Len(t, moves, 1)
+	assert.Contains(t, []string{turn.Notation(), mirrored.Notation()}, moves[0].Turn.Notation())
+	assert.Equal(t, 3, moves[0].Weight)
+
+	// Nothing is stored for the other team
+	assert.Empty(t, book.Lookup(board, 2))
+}
+
+func TestBookSaveLoad(t *testing.T) {
+	sim := santorini.NewSeededSimulator(0, 1, logrus.StandardLogger(), NewRandomBot, NewRandomBot)
+	sim.Run()
+
+	book := NewBook()
+	book.AddGame(sim.Start, sim.Board.Moves, sim.Board.Victor, 6)
+	assert.Len(t, book.Entries, 3)
+
+	path := filepath.Join(t.TempDir(), "openings.book")
+	assert.NoError(t, book.Save(path))
+	loaded, err := LoadBook(path)
+	assert.NoError(t, err)
+	assert.Equal(t, book, loaded)
+}
+
+func TestBookBot(t *testing.T) {
+	board := santorini.DefaultPosition(2)
+	turn := santorini.Turn{Team: 1, Worker: 2, MoveTo: board.GetTile(2, 4), Build: board.GetTile(1, 4)}
+	book := NewBook()
+	book.Add(board, turn, 1)
+
+	bot := NewBookBot(book, NewBasicBot)(1, board, nil)
+	selected := bot.SelectTurn()
+	moves := book.Lookup(board, 1)
+	assert.Equal(t, moves[0].Turn, *selected)
+
+	// Once out of book, the other bot plays
+	board.PlayTurn(*selected)
+	assert.Equal(t, NewBasicBot(2, board, nil).SelectTurn(), NewBookBot(book, NewBasicBot)(2, board, nil).SelectTurn())
+}
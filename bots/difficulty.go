@@ -0,0 +1,178 @@
+package bots
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	santorini "santorini/pkg"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	Register(BotEntry{
+		Name:        "DifficultyBot",
+		Description: "Makes another bot play worse with random turns and missed threats",
+		Options: []BotOption{
+			{Name: "epsilon", Type: "float", Default: "0", Description: "Chance of playing a random turn"},
+			{Name: "threats", Type: "float", Default: "0", Description: "Chance of not noticing the enemy can win"},
+			{Name: "depth", Type: "int", Default: "0", Description: "Weaken a MinimaxBot searching this many turns ahead instead of bot, 0 to use bot"},
+			{Name: "bot", Type: "string", Default: "BasicBot", Description: "Bot to weaken"},
+		},
+		New: func(opts BotOptions) (santorini.BotInitializer, error) {
+			if depth := opts.Int("depth"); depth > 0 {
+				return NewDifficultyBot(opts.Float("epsilon"), opts.Float("threats"), NewMinimaxBot(depth, HeuristicEvaluator{})), nil
+			}
+			bot, err := Build(opts.String("bot"))
+			if err != nil {
+				return nil, err
+			}
+			return NewDifficultyBot(opts.Float("epsilon"), opts.Float("threats"), bot), nil
+		},
+	})
+}
+
+// Level is a preset difficulty for playing against humans
+type Level struct {
+	Name string
+	Spec string // The bot to play, see Build
+	// Measured with cmd/calibrate over 200 games from random openings, the share of games the
+	// level wins against each bot and against the level before it
+	VsRandom   float64
+	VsBasic    float64
+	VsPrevious float64
+}
+
+// Levels from easiest to hardest. Each level searches at least as deep as the one before it
+// and makes fewer mistakes, so it should beat the level before it in most games
+var Levels = []Level{
+	{Name: "beginner", Spec: "DifficultyBot:depth=1,epsilon=0.5,threats=1", VsRandom: 0.85, VsBasic: 0},
+	{Name: "easy", Spec: "DifficultyBot:depth=1,epsilon=0.2,threats=0.5", VsRandom: 0.96, VsBasic: 0.01, VsPrevious: 0.86},
+	{Name: "medium", Spec: "DifficultyBot:depth=2,epsilon=0.25,threats=0.5", VsRandom: 0.98, VsBasic: 0.1, VsPrevious: 0.6},
+	{Name: "hard", Spec: "DifficultyBot:depth=2,epsilon=0.1,threats=0.25", VsRandom: 0.99, VsBasic: 0.45, VsPrevious: 0.76},
+	{Name: "expert", Spec: "MinimaxBot:depth=2", VsRandom: 1, VsBasic: 0.76, VsPrevious: 0.7},
+}
+
+// FindLevel returns the bot for the level with the name
+func FindLevel(name string) (santorini.BotInitializer, error) {
+	for _, level := range Levels {
+		if level.Name == name {
+			return Build(level.Spec)
+		}
+	}
+	return nil, fmt.Errorf("%s is not a difficulty level", name)
+}
+
+// DifficultyBot lets another bot choose its turns, but sometimes plays at random or misses
+// that the enemy is about to win
+type DifficultyBot struct {
+	Bot     santorini.TurnSelector
+	Team    int
+	Board   *santorini.Board
+	Epsilon float64 // Chance of playing a random turn
+	Threats float64 // Chance of not blocking an enemy win the bot wanted to block
+
+	rng     *rand.Rand
+	mistake string // The mistake made on the last turn, if any
+}
+
+// NewDifficultyBot returns an initializer that weakens bot
+func NewDifficultyBot(epsilon, threats float64, bot santorini.BotInitializer) santorini.BotInitializer {
+	return func(team int, board *santorini.Board, logger *logrus.Logger) santorini.TurnSelector {
+		return &DifficultyBot{
+			Bot:     bot(team, board, logger),
+			Team:    team,
+			Board:   board,
+			Epsilon: epsilon,
+			Threats: threats,
+			rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		}
+	}
+}
+
+func (d *DifficultyBot) Name() string {
+	return d.Bot.Name() + "+Mistakes"
+}
+
+func (d *DifficultyBot) IsDeterministic() bool {
+	return d.Epsilon == 0 && d.Threats == 0 && d.Bot.IsDeterministic()
+}
+
+// Seed the mistakes, and the bot being weakened
+func (d *DifficultyBot) Seed(seed int64) {
+	d.rng.Seed(seed)
+	if bot, ok := d.Bot.(santorini.Seedable); ok {
+		bot.Seed(d.rng.Int63())
+	}
+}
+
+func (d *DifficultyBot) SelectTurn() *santorini.Turn {
+	d.mistake = ""
+	candidates := d.Board.GetValidTurns(d.Team)
+	if len(candidates) == 0 {
+		return nil
+	}
+	if d.rng.Float64() < d.Epsilon {
+		d.mistake = "random turn"
+		return &candidates[d.rng.Intn(len(candidates))]
+	}
+
+	turn := d.Bot.SelectTurn()
+	if turn == nil || !d.blocks(*turn) || d.rng.Float64() >= d.Threats {
+		return turn
+	}
+
+	// Play as if the threat was not there
+	others := make([]santorini.Turn, 0, len(candidates))
+	for _, candidate := range candidates {
+		if !d.blocks(candidate) {
+			others = append(others, candidate)
+		}
+	}
+	if len(others) == 0 {
+		return turn
+	}
+	d.mistake = "missed a threat"
+	return &others[d.rng.Intn(len(others))]
+}
+
+// blocks returns true if an enemy can win now, but not after the turn
+func (d *DifficultyBot) blocks(turn santorini.Turn) bool {
+	if turn.IsVictory() {
+		return false
+	}
+	board := play(d.Board, turn)
+	for _, enemy := range enemies(d.Board, d.Team) {
+		if canWin(d.Board, enemy) && !canWin(board, enemy) {
+			return true
+		}
+	}
+	return false
+}
+
+// Explain names the mistake, or lets the weakened bot explain its turn
+func (d *DifficultyBot) Explain(candidates int) *santorini.Explanation {
+	if d.mistake != "" {
+		return &santorini.Explanation{Reason: d.mistake}
+	}
+	if explainer, ok := d.Bot.(santorini.Explainer); ok {
+		return explainer.Explain(candidates)
+	}
+	return nil
+}
+
+// Interrupt the bot being weakened
+func (d *DifficultyBot) Interrupt() {
+	if bot, ok := d.Bot.(santorini.Interrupter); ok {
+		bot.Interrupt()
+	}
+}
+
+// Close the bot being weakened
+func (d *DifficultyBot) Close() error {
+	if closer, ok := d.Bot.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
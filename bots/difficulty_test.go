@@ -0,0 +1,35 @@
+package bots
+
+import (
+	santorini "santorini/pkg"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDifficultyBotMissesThreats(t *testing.T) {
+	// Team 2 can climb from c2 to d2 unless team 1 builds on it
+	board, team, err := santorini.ParseNotation("00000/00230/00000/00000/00000 1.1:e1,1.2:a5,2.1:c2,2.2:e5 1")
+	assert.NoError(t, err)
+
+	careful := NewDifficultyBot(0, 0, NewBasicBot)(team, board, logrus.StandardLogger()).(*DifficultyBot)
+	turn := careful.SelectTurn()
+	assert.True(t, careful.blocks(*turn))
+	assert.Equal(t, "", careful.mistake)
+
+	careless := NewDifficultyBot(0, 1, NewBasicBot)(team, board, logrus.StandardLogger()).(*DifficultyBot)
+	careless.Seed(1)
+	turn = careless.SelectTurn()
+	assert.False(t, careless.blocks(*turn))
+	assert.Equal(t, "missed a threat", careless.Explain(3).Reason)
+}
+
+func TestLevelsBuild(t *testing.T) {
+	for _, level := range Levels {
+		_, err := FindLevel(level.Name)
+		assert.NoError(t, err, level.Name)
+	}
+	_, err := FindLevel("impossible")
+	assert.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package bots
+
+import (
+	"math"
+	santorini "santorini/pkg"
+	"santorini/pkg/nn"
+)
+
+// Evaluator scores a position for a team, from -1 (lost) to 1 (won)
+type Evaluator interface {
+	Evaluate(board *santorini.Board, team int) float64
+}
+
+// HeuristicEvaluator prefers high workers with room to move and somewhere to climb
+type HeuristicEvaluator struct{}
+
+func (HeuristicEvaluator) Evaluate(board *santorini.Board, team int) float64 {
+	score := 0.0
+	for _, tile := range board.Tiles {
+		if !tile.IsOccupied() {
+			continue
+		}
+		worker := 3 * float64(tile.GetHeight())
+		for _, move := range board.GetMoveableTiles(tile) {
+			worker += 0.5
+			if move.GetHeight() > tile.GetHeight() {
+				worker += 1
+			}
+		}
+		if tile.GetTeam() == team {
+			score += worker
+		} else {
+			score -= worker
+		}
+	}
+	return math.Tanh(score / 20)
+}
+
+// NetworkEvaluator scores positions with the value head of a neural network
+type NetworkEvaluator struct {
+	Net *nn.Network
+}
+
+func (e NetworkEvaluator) Evaluate(board *santorini.Board, team int) float64 {
+	value, _ := e.Net.Evaluate(nn.Encode(board, team))
+	return value
+}
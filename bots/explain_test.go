@@ -0,0 +1,45 @@
+package bots
+
+import (
+	santorini "santorini/pkg"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplanationsMatchTurns(t *testing.T) {
+	for _, bot := range []santorini.BotInitializer{NewBasicBot, NewKyleBot, NewMinimaxBot(2, HeuristicEvaluator{})} {
+		sim := santorini.NewSeededSimulator(0, 1, logrus.StandardLogger(), bot, NewRandomBot)
+		sim.Explain = true
+		sim.Run()
+		name := sim.Teams[0].Name()
+		assert.Len(t, sim.Explanations, len(sim.Board.Moves), name)
+		for i, turn := range sim.Board.Moves {
+			explanation := sim.Explanations[i]
+			if turn.Team == 2 {
+				assert.Nil(t, explanation, name)
+				continue
+			}
+			assert.NotNil(t, explanation, name)
+			assert.Equal(t, turn, explanation.Candidates[0].Turn, name)
+		}
+	}
+}
+
+func TestBasicBotFeaturesAddUp(t *testing.T) {
+	board := santorini.DefaultPosition(2)
+	bot := NewBasicBot(1, board, logrus.StandardLogger())
+	turn := bot.SelectTurn()
+	explanation := bot.(santorini.Explainer).Explain(3)
+	assert.Equal(t, "ranked", explanation.Reason)
+	assert.Len(t, explanation.Candidates, 3)
+	assert.Equal(t, *turn, explanation.Candidates[0].Turn)
+	for _, c := range explanation.Candidates {
+		sum := 0.0
+		for _, value := range c.Features {
+			sum += value
+		}
+		assert.Equal(t, c.Score, sum)
+	}
+}
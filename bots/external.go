@@ -0,0 +1,115 @@
+package bots
+
+import (
+	"fmt"
+	santorini "santorini/pkg"
+	"santorini/pkg/engine"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ExternalBot plays the turns chosen by an engine running in another process. See the engine package for the protocol
+type ExternalBot struct {
+	Team     int
+	Board    *santorini.Board
+	Command  string
+	Args     []string
+	Options  map[string]string // Sent to the engine with setoption after the handshake
+	MoveTime time.Duration     // How long the engine may think about each turn
+
+	logger *logrus.Logger
+	client *engine.Client
+	name   string
+}
+
+// NewExternalBot returns an initializer for a bot that launches the command as an engine
+func NewExternalBot(command string, args ...string) santorini.BotInitializer {
+	return func(team int, board *santorini.Board, logger *logrus.Logger) santorini.TurnSelector {
+		return &ExternalBot{
+			Team:     team,
+			Board:    board,
+			Command:  command,
+			Args:     args,
+			MoveTime: time.Second,
+			logger:   logger,
+		}
+	}
+}
+
+// start the engine if it is not already running
+func (e *ExternalBot) start() error {
+	if e.client != nil {
+		return nil
+	}
+	client, err := engine.Start(e.Command, e.Args...)
+	if err != nil {
+		return err
+	}
+	client.OnInfo = func(info string) {
+		if e.logger != nil {
+			e.logger.Debugf("%s: %s", client.Name, info)
+		}
+	}
+	for name, value := range e.Options {
+		if err := client.SetOption(name, value); err != nil {
+			client.Close()
+			return err
+		}
+	}
+	if err := client.NewGame(); err != nil {
+		client.Close()
+		return err
+	}
+	e.client = client
+	e.name = client.Name
+	return nil
+}
+
+// Name reported by the engine once it has started, until then the command it is started with
+func (e *ExternalBot) Name() string {
+	if e.name == "" {
+		return fmt.Sprintf("ExternalBot(%s)", strings.Join(append([]string{e.Command}, e.Args...), " "))
+	}
+	return e.name
+}
+
+func (e *ExternalBot) IsDeterministic() bool {
+	return false
+}
+
+// SelectTurn asks the engine for a turn, panicking if the engine fails or picks an illegal turn
+func (e *ExternalBot) SelectTurn() *santorini.Turn {
+	if err := e.start(); err != nil {
+		panic(fmt.Errorf("failed to start %s: %w", e.Command, err))
+	}
+	turn, err := e.client.BestMove(e.Board, e.Team, e.MoveTime)
+	if err != nil {
+		panic(fmt.Errorf("%s failed to select a turn: %w", e.client.Name, err))
+	}
+
+	candidates := e.Board.GetValidTurns(e.Team)
+	if turn == nil {
+		if len(candidates) > 0 {
+			panic(fmt.Errorf("%s found no moves but %d are available", e.client.Name, len(candidates)))
+		}
+		return nil
+	}
+	for i, candidate := range candidates {
+		if candidate == *turn {
+			return &candidates[i]
+		}
+	}
+	panic(fmt.Errorf("%s selected an illegal turn %s", e.client.Name, turn.Notation()))
+}
+
+// Close stops the engine
+func (e *ExternalBot) Close() error {
+	if e.client == nil {
+		return nil
+	}
+	err := e.client.Close()
+	e.client = nil
+	return err
+}
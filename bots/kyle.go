@@ -2,27 +2,32 @@ package bots
 
 import (
 	santorini "santorini/pkg"
+	"sort"
 
 	"github.com/sirupsen/logrus"
 )
 
+func init() {
+	Register(BotEntry{
+		Name:        "KyleBot",
+		Description: "Scores every turn by the heights around it",
+		New: func(opts BotOptions) (santorini.BotInitializer, error) {
+			return NewKyleBot, nil
+		},
+	})
+}
+
 const maxDepth = 1
 
 type KyleBot struct {
-	Team      int
-	EnemyTeam int
-	Board     *santorini.Board
+	Team  int
+	Board *santorini.Board
 }
 
 func NewKyleBot(team int, board *santorini.Board, logger *logrus.Logger) santorini.TurnSelector {
-	enemy := 2
-	if team == 2 {
-		enemy = 1
-	}
 	return &KyleBot{
-		Team:      team,
-		EnemyTeam: enemy,
-		Board:     board,
+		Team:  team,
+		Board: board,
 	}
 }
 
@@ -31,31 +36,65 @@ func (bot KyleBot) SelectTurn() *santorini.Turn {
 	if candidates == nil {
 		return nil
 	}
+	index, _ := bot.choose(candidates)
+	return &candidates[index]
+}
+
+// Explain scores every candidate again, since KyleBot keeps no state between turns
+func (bot KyleBot) Explain(n int) *santorini.Explanation {
+	candidates := bot.Board.GetValidTurns(bot.Team)
+	if candidates == nil {
+		return nil
+	}
+	index, reason := bot.choose(candidates)
+	explanation := &santorini.Explanation{Reason: reason}
+	scored := make([]santorini.Candidate, len(candidates))
+	for i, candidate := range candidates {
+		features := make(map[string]float64)
+		scored[i] = santorini.Candidate{Turn: candidate, Score: float64(bot.weigh(candidate, features)), Features: features}
+	}
+	// The chosen turn comes first even when it was not the best weighted
+	explanation.Candidates = append(explanation.Candidates, scored[index])
+	scored = append(scored[:index], scored[index+1:]...)
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+	for i := 0; i < len(scored) && len(explanation.Candidates) < n; i++ {
+		explanation.Candidates = append(explanation.Candidates, scored[i])
+	}
+	return explanation
+}
 
+// choose returns the index of the candidate to play and why
+func (bot KyleBot) choose(candidates []santorini.Turn) (int, string) {
 	var (
 		maxWeight int = -1000
 		bestIndex     = 0
 	)
 
-	// Always block a win if possible
-	enemyCandidates := bot.Board.GetValidTurns(bot.EnemyTeam)
-
+	// Always take a victory turn
 	for index, candidate := range candidates {
-		// Always take a victory turn
 		if candidate.IsVictory() {
-			return &candidate
+			return index, "winning move"
 		}
+	}
 
-		// Always block a win
-		for _, enemyCandidate := range enemyCandidates {
-			if enemyCandidate.IsVictory() {
+	// Always block a win if possible, starting with the enemy that moves next
+	for _, enemy := range enemies(bot.Board, bot.Team) {
+		for _, enemyCandidate := range bot.Board.GetValidTurns(enemy) {
+			if !enemyCandidate.IsVictory() {
+				continue
+			}
+			for index, candidate := range candidates {
 				if enemyCandidate.MoveTo.GetX() == candidate.Build.GetX() && enemyCandidate.MoveTo.GetY() == candidate.Build.GetY() {
-					return &candidates[index]
+					return index, "blocking an enemy win"
 				}
 			}
 		}
+	}
 
-		weight := bot.getWeight(candidate)
+	for index, candidate := range candidates {
+		weight := bot.weigh(candidate, nil)
 
 		if weight > maxWeight {
 			maxWeight = weight
@@ -63,7 +102,7 @@ func (bot KyleBot) SelectTurn() *santorini.Turn {
 		}
 	}
 
-	return &candidates[bestIndex]
+	return bestIndex, "ranked"
 }
 
 func (bot KyleBot) Name() string {
@@ -74,56 +113,50 @@ func (bot KyleBot) IsDeterministic() bool {
 	return true
 }
 
-func (bot KyleBot) copyBoard() santorini.Board {
-	tiles := make([]santorini.Tile, len(bot.Board.Tiles))
-	copy(tiles, bot.Board.Tiles)
-	return santorini.Board{
-		Size:  bot.Board.Size,
-		Tiles: tiles,
-		Teams: map[int]bool{
-			bot.Team:      true,
-			bot.EnemyTeam: true,
-		},
-	}
-}
-
-func (bot KyleBot) getWeight(candidate santorini.Turn) int {
+// weigh scores a candidate, adding what each rule contributed to features unless it is nil
+func (bot KyleBot) weigh(candidate santorini.Turn, features map[string]float64) int {
 	// Initialize Weight
 	weight := 0
+	add := func(feature string, value int) {
+		weight += value
+		if features != nil && value != 0 {
+			features[feature] += float64(value)
+		}
+	}
 
 	// Prefer to move up
-	weight += candidate.MoveTo.GetHeight() * 20
+	add("height", candidate.MoveTo.GetHeight()*20)
 
 	// Prefer to cover the most tiles
-	weight += len(bot.Board.GetSurroundingTiles(candidate.MoveTo.GetX(), candidate.MoveTo.GetY()))
+	add("coverage", len(bot.Board.GetSurroundingTiles(candidate.MoveTo.GetX(), candidate.MoveTo.GetY())))
 
 	// Prefer to build high if no enemies are near
 	if !bot.hasNearbyEnemyWorker(candidate.Team, candidate.Build) {
-		weight += (candidate.Build.GetHeight() + 1) * 10
+		add("build_high", (candidate.Build.GetHeight()+1)*10)
 	}
 
 	// Don't build what you cannot reach
 	if candidate.MoveTo.GetHeight() < candidate.Build.GetHeight() {
-		weight -= 50
+		add("unreachable_build", -50)
 	}
 
 	// Ponder the moves to come
-	thoughtBoard := bot.copyBoard()
-	thoughtBoard.PlayTurn(candidate)
+	thoughtBoard := play(bot.Board, candidate)
 
 	// Prefer moves that enable us to win next turn
 	futureCandidates := thoughtBoard.GetValidTurns(bot.Team)
 	for _, futureCandidate := range futureCandidates {
 		if futureCandidate.IsVictory() {
-			weight += 1000
+			add("next_turn_win", 1000)
 		}
 	}
 
 	// Avoid moves that enable an enemy win next turn
-	futureEnemyCandidates := thoughtBoard.GetValidTurns(bot.EnemyTeam)
-	for _, futureEnemyCandidate := range futureEnemyCandidates {
-		if futureEnemyCandidate.IsVictory() {
-			weight -= 100000
+	for _, enemy := range enemies(thoughtBoard, bot.Team) {
+		for _, futureEnemyCandidate := range thoughtBoard.GetValidTurns(enemy) {
+			if futureEnemyCandidate.IsVictory() {
+				add("enemy_win", -100000)
+			}
 		}
 	}
 
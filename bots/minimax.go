@@ -0,0 +1,387 @@
+package bots
+
+import (
+	"fmt"
+	"math"
+	santorini "santorini/pkg"
+	"santorini/pkg/nn"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	Register(BotEntry{
+		Name:        "MinimaxBot",
+		Description: "Searches a fixed number of turns ahead with alpha-beta pruning",
+		Options: []BotOption{
+			{Name: "depth", Type: "int", Default: "3", Description: "Turns to look ahead"},
+			{Name: "eval", Type: "string", Default: "heuristic", Description: "heuristic, or a network file to score positions with"},
+			{Name: "threads", Type: "int", Default: "1", Description: "Threads to search with"},
+			{Name: "deterministic", Type: "bool", Default: "false", Description: "Pick the same turn whatever the thread count, at the cost of speed"},
+		},
+		New: func(opts BotOptions) (santorini.BotInitializer, error) {
+			if opts.Int("depth") < 1 {
+				return nil, fmt.Errorf("depth must be at least 1")
+			}
+			var eval Evaluator = HeuristicEvaluator{}
+			if opts.String("eval") != "heuristic" {
+				net, err := nn.Load(opts.String("eval"))
+				if err != nil {
+					return nil, err
+				}
+				eval = NetworkEvaluator{Net: net}
+			}
+			bot := NewMinimaxBot(opts.Int("depth"), eval)
+			threads, deterministic := opts.Int("threads"), opts.Bool("deterministic")
+			return func(team int, board *santorini.Board, logger *logrus.Logger) santorini.TurnSelector {
+				m := bot(team, board, logger).(*MinimaxBot)
+				m.Threads, m.Deterministic = threads, deterministic
+				return m
+			}, nil
+		},
+	})
+}
+
+// tableSize is the number of entries in each transposition table
+const tableSize = 1 << 16
+
+// winScore is the score of winning right now. Wins further away score a little less so the bot takes the quickest one
+const winScore = 1000
+
+// MinimaxBot searches every turn to a fixed depth with alpha-beta pruning, scoring the positions it reaches with an Evaluator
+type MinimaxBot struct {
+	Team  int
+	Board *santorini.Board
+	Depth int // Turns to look ahead, including our own
+	Eval  Evaluator
+	// Threads split the turns at the root between them and share a transposition table
+	Threads int
+	// Search every turn at the root with its own table and a full window, so the result does
+	// not depend on how the threads are scheduled
+	Deterministic bool
+
+	logger *logrus.Logger
+	tables []*transpositionTable
+	// The result of the last search, for Explain
+	score     float64
+	pv        []santorini.Turn
+	ponderHit bool
+
+	// Set by Interrupt until the next turn is chosen, read atomically by search
+	abort int32
+	// Set while pondering is being stopped, so an interruption is not lost with it
+	stopPonder int32
+	ponderDone chan struct{}
+	pondered   map[string]pondered
+}
+
+// NewMinimaxBot returns an initializer for bots that search depth turns ahead
+func NewMinimaxBot(depth int, eval Evaluator) santorini.BotInitializer {
+	return func(team int, board *santorini.Board, logger *logrus.Logger) santorini.TurnSelector {
+		return &MinimaxBot{
+			Team:   team,
+			Board:  board,
+			Depth:  depth,
+			Eval:   eval,
+			logger: logger,
+		}
+	}
+}
+
+func (m *MinimaxBot) Name() string {
+	return "MinimaxBot"
+}
+
+func (m *MinimaxBot) IsDeterministic() bool {
+	return m.Threads <= 1 || m.Deterministic
+}
+
+func (m *MinimaxBot) log(fmtstr string, args ...interface{}) {
+	if m.logger != nil {
+		m.logger.Debug("MinimaxBot: ", fmt.Sprintf(fmtstr, args...))
+	}
+}
+
+func (m *MinimaxBot) SelectTurn() *santorini.Turn {
+	m.StopPondering()
+	defer atomic.StoreInt32(&m.abort, 0)
+	result, ok := m.pondered[m.Board.Notation(m.Team)]
+	m.pondered = nil
+	if !ok {
+		result.score, result.pv = m.searchRoot(m.Board)
+	}
+	score, pv := result.score, result.pv
+	m.score, m.pv, m.ponderHit = score, pv, ok
+	if len(pv) == 0 {
+		return nil
+	}
+	m.log("depth %d score %.3f pv %s", m.Depth, score, notations(pv))
+	return &pv[0]
+}
+
+// Interrupt makes the search in progress return the best of the turns it has finished searching.
+// When no search is in progress, the next one returns right away
+func (m *MinimaxBot) Interrupt() {
+	atomic.StoreInt32(&m.abort, 1)
+}
+
+// stopped returns true if the search in progress should return
+func (m *MinimaxBot) stopped() bool {
+	return atomic.LoadInt32(&m.abort) != 0 || atomic.LoadInt32(&m.stopPonder) != 0
+}
+
+// Explain returns the score and principal variation of the last search. Alpha-beta only
+// proves the score of the best turn, so it is the only candidate
+func (m *MinimaxBot) Explain(candidates int) *santorini.Explanation {
+	if len(m.pv) == 0 {
+		return nil
+	}
+	reason := fmt.Sprintf("searched %d turns ahead", m.Depth)
+	if m.ponderHit {
+		reason += " while pondering"
+	}
+	return &santorini.Explanation{
+		Reason:     reason,
+		Candidates: []santorini.Candidate{{Turn: m.pv[0], Score: m.score}},
+		PV:         m.pv,
+	}
+}
+
+// table returns the transposition table of thread i
+func (m *MinimaxBot) table(i int) *transpositionTable {
+	for len(m.tables) <= i {
+		m.tables = append(m.tables, newTranspositionTable(tableSize))
+	}
+	return m.tables[i]
+}
+
+// searchRoot returns the score of the board for the bot, and the turns both teams are expected to take
+func (m *MinimaxBot) searchRoot(board *santorini.Board) (float64, []santorini.Turn) {
+	if m.Threads <= 1 && !m.Deterministic {
+		table := m.table(0)
+		table.clear()
+		return m.search(board, m.Team, m.Depth, 0, math.Inf(-1), math.Inf(1), table)
+	}
+
+	turns := board.GetValidTurns(m.Team)
+	if len(turns) == 0 {
+		return -winScore, nil
+	}
+	for _, turn := range turns {
+		if turn.IsVictory() {
+			return winScore, []santorini.Turn{turn}
+		}
+	}
+	orderTurns(turns)
+
+	type result struct {
+		score float64
+		pv    []santorini.Turn
+		exact bool // False if the search failed low against another thread's score
+	}
+	results := make([]result, len(turns))
+	next := board.NextTeam(m.Team)
+
+	// Best exact score so far, raised by every thread and used as their alpha
+	var mu sync.Mutex
+	alpha := math.Inf(-1)
+
+	threads := m.Threads
+	if threads < 1 {
+		threads = 1
+	}
+	searchTurn := func(i int, table *transpositionTable) {
+		window := math.Inf(-1)
+		if m.Deterministic {
+			table.clear()
+		} else {
+			mu.Lock()
+			window = alpha
+			mu.Unlock()
+		}
+		child := board.Clone()
+		child.PlayTurn(turns[i])
+		score, line := m.searchChild(child, m.Team, next, m.Depth-1, 1, window, math.Inf(1), table)
+		if m.stopped() {
+			// The search was cut short, so the score means nothing
+			return
+		}
+		results[i] = result{score, append([]santorini.Turn{turns[i]}, line...), score > window}
+		if !m.Deterministic {
+			mu.Lock()
+			if score > alpha {
+				alpha = score
+			}
+			mu.Unlock()
+		}
+	}
+
+	first := 0
+	if !m.Deterministic {
+		// Search the most promising turn alone, so the other threads start with a good alpha
+		m.table(0).clear()
+		searchTurn(0, m.table(0))
+		first = 1
+	}
+	indexes := make(chan int)
+	wg := new(sync.WaitGroup)
+	for t := 0; t < threads; t++ {
+		table := m.table(0)
+		if m.Deterministic {
+			table = m.table(t)
+		}
+		wg.Add(1)
+		go func(table *transpositionTable) {
+			defer wg.Done()
+			for i := range indexes {
+				searchTurn(i, table)
+			}
+		}(table)
+	}
+	for i := first; i < len(turns); i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	// The first of the best turns, as the single threaded search would pick
+	best := -1
+	for i, r := range results {
+		if r.exact && (best < 0 || r.score > results[best].score) {
+			best = i
+		}
+	}
+	if best < 0 {
+		// Interrupted before any turn was searched, the most promising one will have to do
+		return 0, turns[:1]
+	}
+	return results[best].score, results[best].pv
+}
+
+// search returns the score of the board for the team, and the turns both teams are expected to take
+func (m *MinimaxBot) search(board *santorini.Board, team, depth, ply int, alpha, beta float64, table *transpositionTable) (float64, []santorini.Turn) {
+	// The root always finds a turn, even when it is interrupted right away
+	if ply > 0 && m.stopped() {
+		return 0, nil
+	}
+	turns := board.GetValidTurns(team)
+	if len(turns) == 0 {
+		if team == m.Team || len(enemies(board, team)) < 2 {
+			return -winScore + float64(ply), nil
+		}
+		// An enemy that cannot move is out of the game, and play passes on
+		child := board.Clone()
+		child.Eliminate(team)
+		return m.searchChild(child, team, child.NextTeam(team), depth, ply, alpha, beta, table)
+	}
+	for _, turn := range turns {
+		if turn.IsVictory() {
+			return winScore - float64(ply), []santorini.Turn{turn}
+		}
+	}
+	if depth == 0 {
+		return m.evaluate(board, team), nil
+	}
+
+	orderTurns(turns)
+
+	// Try the best turn from an earlier search first, and skip the search if its score is good enough
+	key := hashBoard(board, team)
+	order := make([]int, 0, len(turns))
+	if entry, ok := table.get(key); ok && int(entry.best) < len(turns) {
+		score, pv := fromTable(entry.score, ply), []santorini.Turn{turns[entry.best]}
+		if int(entry.depth) >= depth {
+			switch {
+			case entry.kind == exactScore,
+				entry.kind == lowerBound && score >= beta,
+				entry.kind == upperBound && score <= alpha:
+				return score, pv
+			}
+		}
+		order = append(order, int(entry.best))
+	}
+	for i := range turns {
+		if len(order) == 0 || order[0] != i {
+			order = append(order, i)
+		}
+	}
+
+	next := board.NextTeam(team)
+	start := alpha
+	best, bestIndex := math.Inf(-1), 0
+	var pv []santorini.Turn
+	for _, i := range order {
+		child := board.Clone()
+		child.PlayTurn(turns[i])
+		score, line := m.searchChild(child, team, next, depth-1, ply+1, alpha, beta, table)
+		if m.stopped() {
+			// Only the turns searched before the interruption count
+			break
+		}
+		if score > best {
+			best, bestIndex = score, i
+			pv = append([]santorini.Turn{turns[i]}, line...)
+		}
+		if best > alpha {
+			alpha = best
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	if m.stopped() {
+		if pv == nil && ply == 0 {
+			// Interrupted before any turn was searched, the most promising one will have to do
+			return 0, turns[order[0] : order[0]+1]
+		}
+		return best, pv
+	}
+
+	entry := tableEntry{key: key, score: toTable(best, ply), depth: int16(depth), kind: exactScore, best: int16(bestIndex)}
+	if best <= start {
+		entry.kind = upperBound
+	} else if best >= beta {
+		entry.kind = lowerBound
+	}
+	table.put(entry)
+	return best, pv
+}
+
+// searchChild searches the board after team's turn and returns its score for team. The bot
+// assumes its enemies play together against it, so the score only changes sign when play
+// passes between the bot and an enemy
+func (m *MinimaxBot) searchChild(board *santorini.Board, team, next, depth, ply int, alpha, beta float64, table *transpositionTable) (float64, []santorini.Turn) {
+	if (team == m.Team) == (next == m.Team) {
+		return m.search(board, next, depth, ply, alpha, beta, table)
+	}
+	score, pv := m.search(board, next, depth, ply, -beta, -alpha, table)
+	return -score, pv
+}
+
+// evaluate scores the board for the side of the team to move. With more than one enemy the board
+// is scored for the bot, since an enemy's own score would count the other enemies against it
+func (m *MinimaxBot) evaluate(board *santorini.Board, team int) float64 {
+	if team == m.Team || len(board.Teams) <= 2 {
+		return m.Eval.Evaluate(board, team)
+	}
+	return -m.Eval.Evaluate(board, m.Team)
+}
+
+// orderTurns puts climbing turns first, they are the most likely to cause a cutoff
+func orderTurns(turns []santorini.Turn) {
+	sort.SliceStable(turns, func(i, j int) bool {
+		return turns[i].MoveTo.GetHeight() > turns[j].MoveTo.GetHeight()
+	})
+}
+
+func notations(turns []santorini.Turn) string {
+	names := make([]string, len(turns))
+	for i, turn := range turns {
+		names[i] = turn.Notation()
+	}
+	return strings.Join(names, " ")
+}
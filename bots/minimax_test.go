@@ -0,0 +1,28 @@
+package bots
+
+import (
+	"math/rand"
+	santorini "santorini/pkg"
+	"santorini/pkg/nn"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMinimaxBotTakesWin(t *testing.T) {
+	board, team, err := santorini.ParseNotation("00000/00230/00000/00000/00000 1.1:c2,1.2:a5,2.1:e5,2.2:e4 1")
+	assert.NoError(t, err)
+
+	turn := NewMinimaxBot(2, HeuristicEvaluator{})(team, board, nil).SelectTurn()
+	assert.NotNil(t, turn)
+	assert.True(t, turn.IsVictory())
+	assert.Equal(t, "d2", santorini.SquareName(turn.MoveTo.GetX(), turn.MoveTo.GetY()))
+}
+
+func TestNeuralBotPlaysValidTurns(t *testing.T) {
+	net := nn.New(rand.New(rand.NewSource(1)), 5, 8)
+	sim := santorini.NewSeededSimulator(0, 1, logrus.StandardLogger(), NewNeuralBot(net), NewMinimaxBot(1, NetworkEvaluator{Net: net}))
+	sim.Run()
+	assert.NotZero(t, sim.Board.Victor)
+}
@@ -0,0 +1,39 @@
+package bots
+
+import (
+	santorini "santorini/pkg"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBotsBlockTheNextEnemy(t *testing.T) {
+	// Teams 2 and 3 can both climb to level 3, team 2 moves first so b1 has to be domed
+	position := "23000/00000/00000/00000/00032 1.1:b2,1.2:d4,2.1:a1,2.2:c3,3.1:e5,3.2:a5 1"
+	for _, bot := range []santorini.BotInitializer{NewBasicBot, NewKyleBot, NewSeededRandomBot(1), NewMinimaxBot(2, HeuristicEvaluator{})} {
+		board, team, err := santorini.ParseNotation(position)
+		assert.NoError(t, err)
+		b := bot(team, board, logrus.StandardLogger())
+		turn := b.SelectTurn()
+		assert.Equal(t, "b1", santorini.SquareName(turn.Build.GetX(), turn.Build.GetY()), b.Name())
+	}
+}
+
+func TestEnemiesInTurnOrder(t *testing.T) {
+	board := santorini.DefaultPosition(3)
+	assert.Equal(t, []int{3, 1}, enemies(board, 2))
+	board.Eliminate(3)
+	assert.Equal(t, []int{1}, enemies(board, 2))
+	assert.Equal(t, []int{2}, enemies(board, 1))
+}
+
+func TestThreeTeamGamesFinish(t *testing.T) {
+	for _, bot := range []santorini.BotInitializer{NewBasicBot, NewKyleBot, NewMinimaxBot(1, HeuristicEvaluator{})} {
+		for seed := int64(0); seed < 3; seed++ {
+			sim := santorini.NewSeededSimulator(int(seed), seed, logrus.StandardLogger(), bot, NewRandomBot, NewRandomBot)
+			sim.Run()
+			assert.True(t, sim.Board.Victor >= 1 && sim.Board.Victor <= 3)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package bots
+
+import (
+	"errors"
+	"math/rand"
+	santorini "santorini/pkg"
+	"santorini/pkg/nn"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	Register(BotEntry{
+		Name:        "NeuralBot",
+		Description: "Plays the turn a neural network likes best",
+		Options: []BotOption{
+			{Name: "model", Type: "string", Description: "Network file from the trainer"},
+			{Name: "epsilon", Type: "float", Default: "0", Description: "Chance of playing a random turn"},
+		},
+		New: func(opts BotOptions) (santorini.BotInitializer, error) {
+			if opts.String("model") == "" {
+				return nil, errors.New("a model is required")
+			}
+			net, err := nn.Load(opts.String("model"))
+			if err != nil {
+				return nil, err
+			}
+			epsilon := opts.Float("epsilon")
+			bot := NewNeuralBot(net)
+			return func(team int, board *santorini.Board, logger *logrus.Logger) santorini.TurnSelector {
+				b := bot(team, board, logger).(*NeuralBot)
+				b.Epsilon = epsilon
+				return b
+			}, nil
+		},
+	})
+}
+
+// NeuralBot greedily plays the turn its neural network likes best
+type NeuralBot struct {
+	Net   *nn.Network
+	Team  int
+	Board *santorini.Board
+	// How much the policy head counts next to the value of the resulting position
+	PolicyWeight float64
+	// Chance of playing a random turn, to explore new positions during self-play
+	Epsilon float64
+
+	rng *rand.Rand
+	// How the last turn was chosen, for Explain
+	reason string
+	scored []santorini.Candidate
+}
+
+// NewNeuralBot returns an initializer for bots that play with the network
+func NewNeuralBot(net *nn.Network) santorini.BotInitializer {
+	return func(team int, board *santorini.Board, logger *logrus.Logger) santorini.TurnSelector {
+		return &NeuralBot{
+			Net:          net,
+			Team:         team,
+			Board:        board,
+			PolicyWeight: 0.5,
+			rng:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		}
+	}
+}
+
+func (b *NeuralBot) Name() string {
+	return "NeuralBot"
+}
+
+func (b *NeuralBot) IsDeterministic() bool {
+	return b.Epsilon == 0
+}
+
+func (b *NeuralBot) Seed(seed int64) {
+	b.rng.Seed(seed)
+}
+
+func (b *NeuralBot) SelectTurn() *santorini.Turn {
+	candidates := b.Board.GetValidTurns(b.Team)
+	if len(candidates) == 0 {
+		return nil
+	}
+	b.scored = b.scored[:0]
+	for i := range candidates {
+		if candidates[i].IsVictory() {
+			b.reason = "winning move"
+			b.scored = append(b.scored, santorini.Candidate{Turn: candidates[i], Score: 1})
+			return &candidates[i]
+		}
+	}
+	if b.Epsilon > 0 && b.rng.Float64() < b.Epsilon {
+		turn := candidates[b.rng.Intn(len(candidates))]
+		b.reason = "exploring"
+		b.scored = append(b.scored, santorini.Candidate{Turn: turn})
+		return &turn
+	}
+
+	_, policy := b.Net.Evaluate(nn.Encode(b.Board, b.Team))
+	evaluator := NetworkEvaluator{Net: b.Net}
+	best, bestScore := 0, 0.0
+	for i, turn := range candidates {
+		child := b.Board.Clone()
+		child.PlayTurn(turn)
+		// The value of the position is from the point of view of the next team
+		value := -evaluator.Evaluate(child, child.NextTeam(b.Team))
+		prior := 0.0
+		if index := nn.PolicyIndex(b.Board, turn); index >= 0 {
+			prior = policy[index]
+		}
+		score := value + b.PolicyWeight*prior
+		b.scored = append(b.scored, santorini.Candidate{
+			Turn:     turn,
+			Score:    score,
+			Features: map[string]float64{"value": value, "policy": prior},
+		})
+		if i == 0 || score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	b.reason = "best value and policy"
+	return &candidates[best]
+}
+
+// Explain returns the best scored turns from the last choice
+func (b *NeuralBot) Explain(candidates int) *santorini.Explanation {
+	if len(b.scored) == 0 {
+		return nil
+	}
+	scored := make([]santorini.Candidate, len(b.scored))
+	copy(scored, b.scored)
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+	if len(scored) > candidates {
+		scored = scored[:candidates]
+	}
+	return &santorini.Explanation{Reason: b.reason, Candidates: scored}
+}
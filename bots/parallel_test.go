@@ -0,0 +1,46 @@
+package bots
+
+import (
+	"fmt"
+	santorini "santorini/pkg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallelSearchAgrees(t *testing.T) {
+	board, team, err := santorini.ParseNotation("01200/02310/00100/00000/00000 1.1:c2,1.2:c4,2.1:b3,2.2:d3 1")
+	assert.NoError(t, err)
+
+	search := func(threads int, deterministic bool) (*MinimaxBot, santorini.Turn) {
+		bot := NewMinimaxBot(2, HeuristicEvaluator{})(team, board, nil).(*MinimaxBot)
+		bot.Threads, bot.Deterministic = threads, deterministic
+		return bot, *bot.SelectTurn()
+	}
+	sequential, turn := search(1, false)
+
+	// Deterministic searches pick the same turn as the single threaded search
+	for _, threads := range []int{1, 2, 4} {
+		bot, parallelTurn := search(threads, true)
+		assert.Equal(t, turn, parallelTurn, threads)
+		assert.Equal(t, sequential.score, bot.score, threads)
+	}
+
+	// Other searches may pick another turn with the same score
+	bot, _ := search(4, false)
+	assert.Equal(t, sequential.score, bot.score)
+	assert.False(t, bot.IsDeterministic())
+}
+
+func BenchmarkMinimaxBot(b *testing.B) {
+	for _, threads := range []int{1, 4} {
+		b.Run(fmt.Sprintf("threads=%d", threads), func(b *testing.B) {
+			board := santorini.DefaultPosition(2)
+			bot := NewMinimaxBot(3, HeuristicEvaluator{})(1, board, nil).(*MinimaxBot)
+			bot.Threads = threads
+			for i := 0; i < b.N; i++ {
+				bot.SelectTurn()
+			}
+		})
+	}
+}
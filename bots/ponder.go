@@ -0,0 +1,55 @@
+package bots
+
+import (
+	santorini "santorini/pkg"
+	"sync/atomic"
+)
+
+// pondered is a search finished while the opponent was thinking
+type pondered struct {
+	score float64
+	pv    []santorini.Turn
+}
+
+// Ponder searches our answer to each turn the team could play, most promising first, until the
+// team plays or pondering is stopped. The results are keyed by the position after the turn
+func (m *MinimaxBot) Ponder(board *santorini.Board, team int) {
+	m.StopPondering()
+	if team == m.Team || board.IsOver {
+		return
+	}
+
+	// Only the copy is used in the background, the live board keeps changing
+	board = board.Clone()
+	results := make(map[string]pondered)
+	done := make(chan struct{})
+	m.pondered, m.ponderDone = results, done
+
+	go func() {
+		defer close(done)
+		replies := board.GetValidTurns(team)
+		orderTurns(replies)
+		for _, reply := range replies {
+			child := board.Clone()
+			if child.PlayTurn(reply) || child.NextTeam(team) != m.Team {
+				continue
+			}
+			score, pv := m.searchRoot(child)
+			if m.stopped() {
+				return
+			}
+			results[child.Notation(m.Team)] = pondered{score: score, pv: pv}
+		}
+	}()
+}
+
+// StopPondering cancels the background search and waits for it to finish. Finished results are kept for the next turn
+func (m *MinimaxBot) StopPondering() {
+	if m.ponderDone == nil {
+		return
+	}
+	atomic.StoreInt32(&m.stopPonder, 1)
+	<-m.ponderDone
+	atomic.StoreInt32(&m.stopPonder, 0)
+	m.ponderDone = nil
+}
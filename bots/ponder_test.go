@@ -0,0 +1,55 @@
+package bots
+
+import (
+	santorini "santorini/pkg"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPonderHit(t *testing.T) {
+	board := santorini.DefaultPosition(2)
+	bot := NewMinimaxBot(2, HeuristicEvaluator{})(2, board, nil).(*MinimaxBot)
+	opponent := NewKyleBot(1, board, nil)
+
+	bot.Ponder(board, 1)
+	// Give the bot time to ponder every reply
+	<-bot.ponderDone
+	turn := opponent.SelectTurn()
+	board.PlayTurn(*turn)
+	pondered := *bot.SelectTurn()
+	assert.True(t, bot.ponderHit)
+
+	// Pondering finds the same turn as searching from scratch
+	fresh := NewMinimaxBot(2, HeuristicEvaluator{})(2, board, nil).SelectTurn()
+	assert.Equal(t, *fresh, pondered)
+}
+
+func TestStopPondering(t *testing.T) {
+	board := santorini.DefaultPosition(2)
+	bot := NewMinimaxBot(6, HeuristicEvaluator{})(2, board, nil).(*MinimaxBot)
+
+	bot.Ponder(board, 1)
+	// The live board can change while the bot ponders on its copy
+	board.PlayTurn(board.GetValidTurns(1)[0])
+	bot.StopPondering()
+	assert.Nil(t, bot.ponderDone)
+
+	bot.Depth = 1
+	assert.NotNil(t, bot.SelectTurn())
+	assert.False(t, bot.ponderHit)
+}
+
+func TestInterruptWhilePondering(t *testing.T) {
+	board := santorini.DefaultPosition(2)
+	bot := NewMinimaxBot(6, HeuristicEvaluator{})(2, board, nil).(*MinimaxBot)
+
+	bot.Ponder(board, 1)
+	bot.Interrupt()
+	board.PlayTurn(board.GetValidTurns(1)[0])
+	// The interruption outlives pondering, so the search returns right away
+	start := time.Now()
+	assert.NotNil(t, bot.SelectTurn())
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -1,35 +1,57 @@
 package bots
 
 import (
-	"crypto/rand"
-	"math/big"
+	"math/rand"
 	santorini "santorini/pkg"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+func init() {
+	Register(BotEntry{
+		Name:        "RandomBot",
+		Description: "Plays any valid turn",
+		Options: []BotOption{
+			{Name: "seed", Type: "int", Default: "0", Description: "Seed for the turns it picks, 0 for a seed from the clock. Simulations add it to the seed of each game"},
+		},
+		New: func(opts BotOptions) (santorini.BotInitializer, error) {
+			if opts.Int("seed") == 0 {
+				return NewRandomBot, nil
+			}
+			return NewSeededRandomBot(int64(opts.Int("seed"))), nil
+		},
+	})
+}
+
 // RandomSelector will play the game randomly
 type RandomSelector struct {
-	Team       int
-	EnemyTeams []int
-	Board      *santorini.Board
+	Team  int
+	Board *santorini.Board
+
+	rng  *rand.Rand
+	seed int64 // Added to the seeds given by the simulator
 }
 
+// NewRandomBot returns a bot seeded from the clock. The simulator reseeds it so games can be replayed
 func NewRandomBot(team int, board *santorini.Board, logger *logrus.Logger) santorini.TurnSelector {
-	eTeams := make(map[int]bool, 1)
-	for _, tile := range board.Tiles {
-		if tile.IsOccupied() && tile.GetTeam() != team {
-			eTeams[tile.GetTeam()] = true
-		}
-	}
-	enemyTeams := make([]int, 0, len(eTeams))
-	for team, _ := range eTeams {
-		enemyTeams = append(enemyTeams, team)
+	return newRandomBot(team, board, time.Now().UnixNano())
+}
+
+// NewSeededRandomBot returns an initializer for RandomBots that always start from the given seed
+func NewSeededRandomBot(seed int64) santorini.BotInitializer {
+	return func(team int, board *santorini.Board, logger *logrus.Logger) santorini.TurnSelector {
+		bot := newRandomBot(team, board, seed)
+		bot.seed = seed
+		return bot
 	}
+}
+
+func newRandomBot(team int, board *santorini.Board, seed int64) *RandomSelector {
 	return &RandomSelector{
-		Team:       team,
-		Board:      board,
-		EnemyTeams: enemyTeams,
+		Team:  team,
+		Board: board,
+		rng:   rand.New(rand.NewSource(seed)),
 	}
 }
 
@@ -41,6 +63,11 @@ func (r RandomSelector) IsDeterministic() bool {
 	return false
 }
 
+// Seed restarts the random number generator, offset by the bot's own seed so it picks other turns than an unseeded bot
+func (r *RandomSelector) Seed(seed int64) {
+	r.rng.Seed(seed + r.seed)
+}
+
 func (r RandomSelector) testReturn(t *santorini.Turn) *santorini.Turn {
 	if t.Team != r.Team {
 		panic("bad team")
@@ -59,8 +86,8 @@ func (r RandomSelector) SelectTurn() *santorini.Turn {
 		return r.testReturn(&candidates[0])
 	}
 
-	// Always block a win if possible
-	for _, team := range r.EnemyTeams {
+	// Always block a win if possible, starting with the enemy that moves next
+	for _, team := range enemies(r.Board, r.Team) {
 		for _, turn := range r.Board.GetValidTurns(team) {
 			if turn.IsVictory() {
 				// Find a move that can block it
@@ -74,10 +101,5 @@ func (r RandomSelector) SelectTurn() *santorini.Turn {
 		}
 	}
 
-	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(candidates)-1)))
-	if err != nil {
-		panic(err)
-	}
-
-	return r.testReturn(&candidates[n.Int64()])
+	return r.testReturn(&candidates[r.rng.Intn(len(candidates))])
 }
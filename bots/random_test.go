@@ -0,0 +1,41 @@
+package bots
+
+import (
+	santorini "santorini/pkg"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeededSimulationsReplay(t *testing.T) {
+	sim1 := santorini.NewSeededSimulator(0, 42, logrus.StandardLogger(), NewRandomBot, NewRandomBot)
+	sim2 := santorini.NewSeededSimulator(0, 42, logrus.StandardLogger(), NewRandomBot, NewRandomBot)
+	sim1.Run()
+	sim2.Run()
+	assert.Equal(t, sim1.Board.Moves, sim2.Board.Moves)
+	assert.Equal(t, sim1.Board.Victor, sim2.Board.Victor)
+}
+
+func TestSeededRandomBotInSimulations(t *testing.T) {
+	play := func(bot santorini.BotInitializer) []santorini.Turn {
+		sim := santorini.NewSeededSimulator(0, 42, logrus.StandardLogger(), bot, NewKyleBot)
+		sim.Run()
+		return sim.Board.Moves
+	}
+	// The bot's seed changes its turns, and games with it can still be replayed
+	assert.NotEqual(t, play(NewRandomBot), play(NewSeededRandomBot(7)))
+	assert.Equal(t, play(NewSeededRandomBot(7)), play(NewSeededRandomBot(7)))
+}
+
+func TestRandomBotPicksEveryCandidate(t *testing.T) {
+	board := santorini.DefaultPosition(2)
+	bot := NewSeededRandomBot(1)(1, board, nil)
+	candidates := board.GetValidTurns(1)
+
+	seen := make(map[santorini.Turn]bool)
+	for i := 0; i < 5000; i++ {
+		seen[*bot.SelectTurn()] = true
+	}
+	assert.Len(t, seen, len(candidates))
+}
@@ -0,0 +1,206 @@
+package bots
+
+import (
+	"fmt"
+	santorini "santorini/pkg"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BotOption is a setting a registered bot accepts
+type BotOption struct {
+	Name        string
+	Type        string // int, float, bool or string, the same types engines advertise
+	Default     string
+	Description string
+}
+
+// check returns an error if the value does not fit the option's type
+func (o BotOption) check(value string) error {
+	var err error
+	switch o.Type {
+	case "int":
+		_, err = strconv.Atoi(value)
+	case "float":
+		_, err = strconv.ParseFloat(value, 64)
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	}
+	if err != nil {
+		return fmt.Errorf("option %s must be of type %s, not %q", o.Name, o.Type, value)
+	}
+	return nil
+}
+
+// BotOptions holds the value of every option of a bot, already checked against their types
+type BotOptions map[string]string
+
+func (o BotOptions) Int(name string) int {
+	value, _ := strconv.Atoi(o[name])
+	return value
+}
+
+func (o BotOptions) Float(name string) float64 {
+	value, _ := strconv.ParseFloat(o[name], 64)
+	return value
+}
+
+func (o BotOptions) Bool(name string) bool {
+	value, _ := strconv.ParseBool(o[name])
+	return value
+}
+
+func (o BotOptions) String(name string) string {
+	return o[name]
+}
+
+// BotEntry describes a bot and how to build it from its options
+type BotEntry struct {
+	Name        string
+	Description string
+	Options     []BotOption
+	New         func(opts BotOptions) (santorini.BotInitializer, error)
+}
+
+// Build checks the values against the options, fills in defaults and builds the bot
+func (e BotEntry) Build(values map[string]string) (santorini.BotInitializer, error) {
+	opts := make(BotOptions, len(e.Options))
+	for _, o := range e.Options {
+		opts[o.Name] = o.Default
+	}
+	for name, value := range values {
+		option, ok := e.option(name)
+		if !ok {
+			return nil, fmt.Errorf("%s has no option %s", e.Name, name)
+		}
+		if err := option.check(value); err != nil {
+			return nil, err
+		}
+		opts[name] = value
+	}
+	bot, err := e.New(opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", e.Name, err)
+	}
+	return bot, nil
+}
+
+func (e BotEntry) option(name string) (BotOption, bool) {
+	for _, o := range e.Options {
+		if o.Name == name {
+			return o, true
+		}
+	}
+	return BotOption{}, false
+}
+
+var registry = make(map[string]BotEntry)
+
+// Register adds a bot to the registry. It panics if the name is taken, so call it from init
+func Register(entry BotEntry) {
+	if _, ok := registry[entry.Name]; ok {
+		panic(fmt.Sprintf("bot %s is already registered", entry.Name))
+	}
+	registry[entry.Name] = entry
+}
+
+// Registered returns every registered bot, sorted by name
+func Registered() []BotEntry {
+	entries := make([]BotEntry, 0, len(registry))
+	for _, entry := range registry {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// Lookup returns the registered bot with the name
+func Lookup(name string) (BotEntry, bool) {
+	entry, ok := registry[name]
+	return entry, ok
+}
+
+// ParseSpec splits a spec such as "MinimaxBot:depth=4,eval=network.json" into the bot's name and option values.
+// A bot option takes the rest of the spec, so wrapped bots can have options of their own:
+// "BookBot:book=book.txt,bot=MinimaxBot:depth=4,eval=network.json"
+func ParseSpec(spec string) (string, map[string]string, error) {
+	i := strings.Index(spec, ":")
+	if i < 0 {
+		return spec, nil, nil
+	}
+	name, rest := spec[:i], spec[i+1:]
+	values := make(map[string]string)
+	for rest != "" {
+		eq := strings.Index(rest, "=")
+		if eq < 0 {
+			return "", nil, fmt.Errorf("option %q in %s has no value", rest, spec)
+		}
+		key, value := rest[:eq], rest[eq+1:]
+		rest = ""
+		if comma := strings.Index(value, ","); comma >= 0 && key != "bot" {
+			value, rest = value[:comma], value[comma+1:]
+		}
+		values[key] = value
+	}
+	return name, values, nil
+}
+
+// Build returns the bot for a spec, or an external engine when the spec starts with "exec:"
+func Build(spec string) (santorini.BotInitializer, error) {
+	if strings.HasPrefix(spec, "exec:") {
+		command := strings.Fields(strings.TrimPrefix(spec, "exec:"))
+		if len(command) == 0 {
+			return nil, fmt.Errorf("%s has no command", spec)
+		}
+		return NewExternalBot(command[0], command[1:]...), nil
+	}
+	name, values, err := ParseSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a known bot", name)
+	}
+	return entry.Build(values)
+}
+
+// CanonicalSpec returns the spec written the same way for every spec of the same bot: options at
+// their default value are left out and the rest are sorted, with the bot option last
+func CanonicalSpec(spec string) (string, error) {
+	if strings.HasPrefix(spec, "exec:") {
+		return spec, nil
+	}
+	name, values, err := ParseSpec(spec)
+	if err != nil {
+		return "", err
+	}
+	entry, ok := Lookup(name)
+	if !ok {
+		return "", fmt.Errorf("%s is not a known bot", name)
+	}
+	var options []string
+	for _, o := range entry.Options {
+		value, ok := values[o.Name]
+		if !ok || value == o.Default || o.Name == "bot" {
+			continue
+		}
+		options = append(options, o.Name+"="+value)
+	}
+	sort.Strings(options)
+	if bot, ok := values["bot"]; ok {
+		if bot, err = CanonicalSpec(bot); err != nil {
+			return "", err
+		}
+		if option, _ := entry.option("bot"); bot != option.Default {
+			options = append(options, "bot="+bot)
+		}
+	}
+	if len(options) == 0 {
+		return name, nil
+	}
+	return name + ":" + strings.Join(options, ","), nil
+}
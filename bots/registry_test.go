@@ -0,0 +1,85 @@
+package bots
+
+import (
+	santorini "santorini/pkg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSpec(t *testing.T) {
+	name, values, err := ParseSpec("BasicBot")
+	assert.NoError(t, err)
+	assert.Equal(t, "BasicBot", name)
+	assert.Empty(t, values)
+
+	name, values, err = ParseSpec("MinimaxBot:depth=4,eval=network.json")
+	assert.NoError(t, err)
+	assert.Equal(t, "MinimaxBot", name)
+	assert.Equal(t, map[string]string{"depth": "4", "eval": "network.json"}, values)
+
+	name, values, err = ParseSpec("BookBot:book=book.txt,bot=MinimaxBot:depth=4,eval=network.json")
+	assert.NoError(t, err)
+	assert.Equal(t, "BookBot", name)
+	assert.Equal(t, map[string]string{"book": "book.txt", "bot": "MinimaxBot:depth=4,eval=network.json"}, values)
+
+	_, _, err = ParseSpec("MinimaxBot:depth")
+	assert.Error(t, err)
+}
+
+func TestBuild(t *testing.T) {
+	bot, err := Build("MinimaxBot:depth=2")
+	assert.NoError(t, err)
+	minimax := bot(1, santorini.DefaultPosition(2), nil).(*MinimaxBot)
+	assert.Equal(t, 2, minimax.Depth)
+	assert.Equal(t, HeuristicEvaluator{}, minimax.Eval)
+
+	for _, spec := range []string{
+		"NoBot",
+		"MinimaxBot:depth=two",
+		"MinimaxBot:depth=0",
+		"MinimaxBot:width=2",
+		"BookBot",
+		"BookBot:book=missing.txt",
+		"exec:",
+	} {
+		_, err := Build(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestRegisteredBotsBuildWithDefaults(t *testing.T) {
+	for _, entry := range Registered() {
+		if entry.Name == "BookBot" || entry.Name == "NeuralBot" || entry.Name == "TablebaseBot" {
+			// These need a file to load
+			continue
+		}
+		bot, err := entry.Build(nil)
+		assert.NoError(t, err, entry.Name)
+		name := bot(1, santorini.DefaultPosition(2), nil).Name()
+		if entry.Name == "DifficultyBot" {
+			// Wrappers are named after the bot they wrap
+			assert.Equal(t, "BasicBot+Mistakes", name)
+			continue
+		}
+		assert.Equal(t, entry.Name, name)
+	}
+}
+
+func TestCanonicalSpec(t *testing.T) {
+	for spec, canonical := range map[string]string{
+		"MinimaxBot":                                "MinimaxBot",
+		"MinimaxBot:depth=3":                        "MinimaxBot",
+		"MinimaxBot:threads=2,depth=4":              "MinimaxBot:depth=4,threads=2",
+		"DifficultyBot:epsilon=0.3,bot=BasicBot":    "DifficultyBot:epsilon=0.3",
+		"DifficultyBot:epsilon=0.3,bot=KyleBot":     "DifficultyBot:epsilon=0.3,bot=KyleBot",
+		"BookBot:book=b.txt,bot=MinimaxBot:depth=3": "BookBot:book=b.txt,bot=MinimaxBot",
+		"exec:engine --fast":                        "exec:engine --fast",
+	} {
+		got, err := CanonicalSpec(spec)
+		assert.NoError(t, err, spec)
+		assert.Equal(t, canonical, got, spec)
+	}
+	_, err := CanonicalSpec("NoBot")
+	assert.Error(t, err)
+}
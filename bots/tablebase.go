@@ -0,0 +1,128 @@
+package bots
+
+import (
+	"errors"
+	"io"
+	santorini "santorini/pkg"
+	"santorini/pkg/solver"
+
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	Register(BotEntry{
+		Name:        "TablebaseBot",
+		Description: "Plays perfectly in solved positions, and lets another bot play the rest",
+		Options: []BotOption{
+			{Name: "tablebase", Type: "string", Description: "Tablebase file from the solver"},
+			{Name: "bot", Type: "string", Default: "BasicBot", Description: "Bot to play unsolved positions"},
+		},
+		New: func(opts BotOptions) (santorini.BotInitializer, error) {
+			if opts.String("tablebase") == "" {
+				return nil, errors.New("a tablebase is required")
+			}
+			table, err := solver.LoadTablebase(opts.String("tablebase"))
+			if err != nil {
+				return nil, err
+			}
+			bot, err := Build(opts.String("bot"))
+			if err != nil {
+				return nil, err
+			}
+			return NewTablebaseBot(table, bot), nil
+		},
+	})
+}
+
+// TablebaseBot plays perfectly in positions proven by the tablebase, and lets another bot play the rest
+type TablebaseBot struct {
+	Table *solver.Tablebase
+	Bot   santorini.TurnSelector
+	Team  int
+	Board *santorini.Board
+
+	// The proven result of the last turn, nil if the bot played it
+	entry *solver.Entry
+	turn  *santorini.Turn
+}
+
+// NewTablebaseBot returns an initializer for a bot that probes the tablebase before falling back to bot
+func NewTablebaseBot(table *solver.Tablebase, bot santorini.BotInitializer) santorini.BotInitializer {
+	return func(team int, board *santorini.Board, logger *logrus.Logger) santorini.TurnSelector {
+		return &TablebaseBot{
+			Table: table,
+			Bot:   bot(team, board, logger),
+			Team:  team,
+			Board: board,
+		}
+	}
+}
+
+func (t *TablebaseBot) Name() string {
+	return t.Bot.Name() + "+Tablebase"
+}
+
+func (t *TablebaseBot) IsDeterministic() bool {
+	return t.Bot.IsDeterministic()
+}
+
+func (t *TablebaseBot) SelectTurn() *santorini.Turn {
+	t.entry, t.turn = nil, nil
+	// The tablebase is solved for two teams
+	if t.Board.Size == t.Table.Size && len(t.Board.Teams) == 2 {
+		if turn, entry, ok := t.Table.BestTurn(t.Board, t.Team); ok && turn != nil {
+			t.entry, t.turn = &entry, turn
+			return turn
+		}
+	}
+	return t.Bot.SelectTurn()
+}
+
+// Explain gives the proven result of the last turn, or asks the bot when the position was not solved
+func (t *TablebaseBot) Explain(candidates int) *santorini.Explanation {
+	if t.entry == nil {
+		if explainer, ok := t.Bot.(santorini.Explainer); ok {
+			return explainer.Explain(candidates)
+		}
+		return nil
+	}
+	return &santorini.Explanation{
+		Reason:     "tablebase " + t.entry.String(),
+		Candidates: []santorini.Candidate{{Turn: *t.turn}},
+	}
+}
+
+// Seed the bot the tablebase falls back to
+func (t *TablebaseBot) Seed(seed int64) {
+	if bot, ok := t.Bot.(santorini.Seedable); ok {
+		bot.Seed(seed)
+	}
+}
+
+// Ponder with the bot the tablebase falls back to
+func (t *TablebaseBot) Ponder(board *santorini.Board, team int) {
+	if bot, ok := t.Bot.(santorini.Ponderer); ok {
+		bot.Ponder(board, team)
+	}
+}
+
+func (t *TablebaseBot) StopPondering() {
+	if bot, ok := t.Bot.(santorini.Ponderer); ok {
+		bot.StopPondering()
+	}
+}
+
+// Interrupt the bot the tablebase falls back to
+func (t *TablebaseBot) Interrupt() {
+	if bot, ok := t.Bot.(santorini.Interrupter); ok {
+		bot.Interrupt()
+	}
+}
+
+// Close the bot the tablebase falls back to
+func (t *TablebaseBot) Close() error {
+	if closer, ok := t.Bot.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package bots
+
+import (
+	santorini "santorini/pkg"
+)
+
+// play returns the board after the turn. The move history is not copied, so it is cheap enough
+// to call for every turn when looking ahead
+func play(board *santorini.Board, turn santorini.Turn) *santorini.Board {
+	next := *board
+	next.Tiles = board.GetTiles()
+	next.Moves = nil
+	// GetValidTurns writes to Teams, so the copy needs its own
+	next.Teams = make(map[int]bool, len(board.Teams))
+	for team, playing := range board.Teams {
+		next.Teams[team] = playing
+	}
+	next.PlayTurn(turn)
+	return &next
+}
+
+// enemies returns the other teams with workers on the board, in the order they move after team
+func enemies(board *santorini.Board, team int) []int {
+	onBoard := make(map[int]bool)
+	last := team
+	for _, tile := range board.Tiles {
+		if tile.IsOccupied() {
+			onBoard[tile.GetTeam()] = true
+			if tile.GetTeam() > last {
+				last = tile.GetTeam()
+			}
+		}
+	}
+	var teams []int
+	for i := 1; i < last; i++ {
+		if enemy := (team+i-1)%last + 1; onBoard[enemy] {
+			teams = append(teams, enemy)
+		}
+	}
+	return teams
+}
+
+// threats returns the tiles the team could climb onto to win, if it was their turn
+func threats(board *santorini.Board, team int) []santorini.Tile {
+	var tiles []santorini.Tile
+	for _, worker := range board.Tiles {
+		if worker.GetTeam() != team || worker.GetHeight() != 2 {
+			continue
+		}
+		for _, tile := range board.GetMoveableTiles(worker) {
+			if tile.GetHeight() == 3 && !containsTile(tiles, tile) {
+				tiles = append(tiles, tile)
+			}
+		}
+	}
+	return tiles
+}
+
+func canWin(board *santorini.Board, team int) bool {
+	return len(threats(board, team)) > 0
+}
+
+func containsTile(tiles []santorini.Tile, tile santorini.Tile) bool {
+	for _, t := range tiles {
+		if t.GetX() == tile.GetX() && t.GetY() == tile.GetY() {
+			return true
+		}
+	}
+	return false
+}
+
+// moveableTiles returns the tiles any of the team's workers can move to
+func moveableTiles(board *santorini.Board, team int) []santorini.Tile {
+	var tiles []santorini.Tile
+	for _, worker := range board.Tiles {
+		if worker.GetTeam() != team {
+			continue
+		}
+		for _, tile := range board.GetMoveableTiles(worker) {
+			if !containsTile(tiles, tile) {
+				tiles = append(tiles, tile)
+			}
+		}
+	}
+	return tiles
+}
+
+// canBuildOn returns true if the team can build on the tile on its next turn
+func canBuildOn(board *santorini.Board, team int, tile santorini.Tile) bool {
+	if tile.IsCapped() {
+		return false
+	}
+	for _, worker := range board.Tiles {
+		if worker.GetTeam() != team {
+			continue
+		}
+		for _, move := range board.GetMoveableTiles(worker) {
+			if move.GetX() == tile.GetX() && move.GetY() == tile.GetY() {
+				continue
+			}
+			if abs(move.GetX()-tile.GetX()) <= 1 && abs(move.GetY()-tile.GetY()) <= 1 {
+				// The tile is free, or is the one the worker is leaving
+				if !tile.IsOccupied() || tile.IsOccupiedBy(worker.GetTeam(), worker.GetWorker()) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// forcesWin returns true if team wins by its next turn whatever the enemies, who move first,
+// play. Either every enemy is trapped, or team threatens more wins than the enemies can dome
+func forcesWin(board *santorini.Board, team int, enemies []int) bool {
+	trapped := true
+	for _, enemy := range enemies {
+		if len(moveableTiles(board, enemy)) > 0 {
+			trapped = false
+		}
+		if canWin(board, enemy) {
+			return false
+		}
+	}
+	if trapped {
+		return true
+	}
+	wins := threats(board, team)
+	if len(wins) == 0 {
+		return false
+	}
+	// Every enemy that can reach one of the tiles domes one of them
+	blockers := 0
+	for _, enemy := range enemies {
+		for _, tile := range wins {
+			if canBuildOn(board, enemy, tile) {
+				blockers++
+				break
+			}
+		}
+	}
+	return len(wins) > blockers
+}
+
+// canForceWin returns true if team, who moves next, has a turn that wins the game by its
+// following turn
+func canForceWin(board *santorini.Board, team int, enemies []int) bool {
+	if canWin(board, team) {
+		return true
+	}
+	// A threat needs a worker on level 2 after the turn, and a trap needs every enemy to have
+	// few enough moves that a single move and build can take them all away
+	if !mayClimb(board, team) {
+		for _, enemy := range enemies {
+			if len(moveableTiles(board, enemy)) > 2 {
+				return false
+			}
+		}
+	}
+	for _, turn := range board.GetValidTurns(team) {
+		if forcesWin(play(board, turn), team, enemies) {
+			return true
+		}
+	}
+	return false
+}
+
+// mayClimb returns true if the team has a worker on level 2, or one that can move up to it
+func mayClimb(board *santorini.Board, team int) bool {
+	for _, worker := range board.Tiles {
+		if worker.GetTeam() != team {
+			continue
+		}
+		if worker.GetHeight() == 2 {
+			return true
+		}
+		for _, tile := range board.GetMoveableTiles(worker) {
+			if tile.GetHeight() == 2 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// trappedWorkers returns how many of the team's workers have nowhere to move
+func trappedWorkers(board *santorini.Board, team int) int {
+	trapped := 0
+	for _, worker := range board.Tiles {
+		if worker.GetTeam() == team && len(board.GetMoveableTiles(worker)) == 0 {
+			trapped++
+		}
+	}
+	return trapped
+}
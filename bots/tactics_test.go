@@ -0,0 +1,92 @@
+package bots
+
+import (
+	santorini "santorini/pkg"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// selectTurn parses the position and returns the turn BasicBot chooses with its explanation
+func selectTurn(t *testing.T, position string) (*santorini.Board, *santorini.Turn, *santorini.Explanation) {
+	board, team, err := santorini.ParseNotation(position)
+	assert.NoError(t, err)
+	bot := NewBasicBot(team, board, logrus.StandardLogger())
+	turn := bot.SelectTurn()
+	return board, turn, bot.(santorini.Explainer).Explain(1)
+}
+
+func TestBasicBotUnblockableClimb(t *testing.T) {
+	// Climbing to b1 and building c1 to level 3 is too far away for team 2 to dome
+	board, turn, explanation := selectTurn(t, "12200/00000/00000/00000/00000 1.1:a1,1.2:a5,2.1:e5,2.2:d5 1")
+	assert.Equal(t, "1.1:b1c1", turn.Notation())
+	assert.Equal(t, "forcing a win", explanation.Reason)
+	assert.True(t, forcesWin(play(board, *turn), 1, []int{2}))
+}
+
+func TestBasicBotDoubleThreat(t *testing.T) {
+	// Team 2 can dome a3 or a2 from b2, but not both
+	board, turn, explanation := selectTurn(t, "00000/20000/32100/00000/00000 1.1:c3,1.2:e5,2.1:b1,2.2:e1 1")
+	assert.Equal(t, "1.1:b3a2", turn.Notation())
+	assert.Equal(t, "forcing a win", explanation.Reason)
+	assert.Len(t, threats(play(board, *turn), 1), 2)
+}
+
+func TestBasicBotTrapsEnemy(t *testing.T) {
+	// Both of team 2's workers can only escape to b2, building it to level 2 traps them
+	board, turn, explanation := selectTurn(t, "04000/01000/44000/00000/00000 1.1:d2,1.2:e5,2.1:a1,2.2:a2 1")
+	assert.Equal(t, "b2", santorini.SquareName(turn.Build.GetX(), turn.Build.GetY()))
+	assert.Equal(t, "forcing a win", explanation.Reason)
+	assert.Equal(t, 2, trappedWorkers(play(board, *turn), 2))
+
+	// Trapping a single worker is ranked up
+	board, _, err := santorini.ParseNotation("04000/41000/00000/00000/00000 1.1:d2,1.2:a5,2.1:a1,2.2:e5 1")
+	assert.NoError(t, err)
+	bot := newBasicBot(1, board, logrus.StandardLogger(), DefaultBasicWeights)
+	bot.update()
+	trap, err := board.ParseTurn("1.1:c2b2")
+	assert.NoError(t, err)
+	features := make(map[string]float64)
+	bot.rank(trap, features)
+	assert.Equal(t, float64(DefaultBasicWeights.TrapEnemy), features["trap_enemy"])
+}
+
+func TestBasicBotAvoidsForcedLoss(t *testing.T) {
+	// Team 2 threatens to climb to b3 and build a2, leaving two tiles to dome
+	board, turn, explanation := selectTurn(t, "00000/20000/32100/00000/00000 1.1:c1,1.2:e5,2.1:c3,2.2:e1 1")
+	assert.Equal(t, "ranked, avoiding a forced loss", explanation.Reason)
+	assert.False(t, canForceWin(play(board, *turn), 2, []int{1}))
+
+	// Only the turns ranked above the best safe one are looked ahead from and dropped
+	bot := newBasicBot(1, board, logrus.StandardLogger(), DefaultBasicWeights)
+	bot.update()
+	bot.sortMoves()
+	safe := bot.bestSafe(bot.turns)
+	assert.Equal(t, *turn, safe[len(safe)-1])
+	for _, dropped := range bot.turns[len(safe):] {
+		assert.True(t, canForceWin(play(board, dropped), 2, []int{1}))
+	}
+}
+
+func TestBasicBotKeepsWorkersMobile(t *testing.T) {
+	// Moving to a1 and building b2 to level 2 would leave the worker stuck in the corner
+	board, _, err := santorini.ParseNotation("04000/41000/00000/00000/00000 1.1:b2,1.2:e5,2.1:c4,2.2:e3 1")
+	assert.NoError(t, err)
+	bot := newBasicBot(1, board, logrus.StandardLogger(), DefaultBasicWeights)
+	bot.update()
+	stuck, err := board.ParseTurn("1.1:a1b2")
+	assert.NoError(t, err)
+	features := make(map[string]float64)
+	bot.rank(stuck, features)
+	assert.Equal(t, float64(DefaultBasicWeights.TrappedWorker), features["trapped_worker"])
+
+	turn := bot.SelectTurn()
+	assert.Equal(t, 0, trappedWorkers(play(board, *turn), 1))
+}
+
+func TestBasicBotTrapped(t *testing.T) {
+	_, turn, explanation := selectTurn(t, "00400/44400/00000/00000/00000 1.1:a1,1.2:b1,2.1:e5,2.2:d5 1")
+	assert.Nil(t, turn)
+	assert.Nil(t, explanation)
+}
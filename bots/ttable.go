@@ -0,0 +1,98 @@
+package bots
+
+import (
+	santorini "santorini/pkg"
+	"sync"
+)
+
+// Kinds of score stored in the transposition table
+const (
+	exactScore = iota
+	lowerBound // The search failed high, the score is at least this
+	upperBound // The search failed low, the score is at most this
+)
+
+// tableLocks is the number of locks guarding the table, each one covers every tableLocks'th slot
+const tableLocks = 64
+
+type tableEntry struct {
+	key   uint64
+	score float64
+	depth int16
+	kind  int8
+	best  int16 // Index of the best turn, after orderTurns
+}
+
+// transpositionTable remembers the scores of positions that were already searched. It is
+// safe to share between threads, writes to the same slot keep whichever entry came last
+type transpositionTable struct {
+	entries []tableEntry
+	locks   [tableLocks]sync.Mutex
+}
+
+func newTranspositionTable(size int) *transpositionTable {
+	return &transpositionTable{entries: make([]tableEntry, size)}
+}
+
+func (t *transpositionTable) get(key uint64) (tableEntry, bool) {
+	i := key % uint64(len(t.entries))
+	lock := &t.locks[i%tableLocks]
+	lock.Lock()
+	entry := t.entries[i]
+	lock.Unlock()
+	return entry, entry.key == key && key != 0
+}
+
+func (t *transpositionTable) put(entry tableEntry) {
+	i := entry.key % uint64(len(t.entries))
+	lock := &t.locks[i%tableLocks]
+	lock.Lock()
+	t.entries[i] = entry
+	lock.Unlock()
+}
+
+func (t *transpositionTable) clear() {
+	for i := range t.entries {
+		t.entries[i] = tableEntry{}
+	}
+}
+
+// hashBoard returns a key for the position and the team to move
+func hashBoard(board *santorini.Board, team int) uint64 {
+	h := mix(uint64(team))
+	for i, tile := range board.Tiles {
+		if tile.GetHeight() == 0 && !tile.IsOccupied() {
+			continue
+		}
+		h ^= mix(uint64(i)<<16 | uint64(tile.GetHeight())<<8 | uint64(tile.GetTeam())<<4 | uint64(tile.GetWorker()))
+	}
+	return h
+}
+
+// mix is the splitmix64 finalizer, it spreads the bits of x over the whole word
+func mix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// toTable stores win scores as the distance from the position rather than from the root, so
+// the entry is right wherever the position is found again
+func toTable(score float64, ply int) float64 {
+	if score > winScore/2 {
+		return score + float64(ply)
+	} else if score < -winScore/2 {
+		return score - float64(ply)
+	}
+	return score
+}
+
+func fromTable(score float64, ply int) float64 {
+	if score > winScore/2 {
+		return score - float64(ply)
+	} else if score < -winScore/2 {
+		return score + float64(ply)
+	}
+	return score
+}
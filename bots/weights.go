@@ -0,0 +1,96 @@
+package bots
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// BasicWeights are the values BasicBot adds to a turn's rank when the turn matches each rule
+type BasicWeights struct {
+	MoveUp         int `json:"move_up"`
+	MoveDownOne    int `json:"move_down_one"`
+	MoveDownTwo    int `json:"move_down_two"`
+	EdgeBuild      int `json:"edge_build"` // Applied for each missing neighbor of the build tile
+	CornerMove     int `json:"corner_move"`
+	FewMoves       int `json:"few_moves"`
+	FewBuilds      int `json:"few_builds"`
+	BuildAbove     int `json:"build_above"`
+	BuildThree     int `json:"build_three"`
+	BuildUp        int `json:"build_up"`
+	BuildOnBlock   int `json:"build_on_block"`
+	BuildNearEnemy int `json:"build_near_enemy"` // Applied for each enemy next to the build tile
+	BuildNearBlock int `json:"build_near_block"` // Applied for each building next to the build tile
+	NearTeammate   int `json:"near_teammate"`
+	StayHigh       int `json:"stay_high"`
+	TrapEnemy      int `json:"trap_enemy"`     // Applied for each enemy worker left with nowhere to move
+	CrampedWorker  int `json:"cramped_worker"` // Applied for each of our workers left with one move
+	TrappedWorker  int `json:"trapped_worker"` // Applied for each of our workers left with nowhere to move
+}
+
+// DefaultBasicWeights are the hand picked weights BasicBot ranks turns with. Ranking a turn plays it
+// to score the trap weights, then BasicBot looks ahead only from its highest ranked turns, dropping
+// them until one does not let the enemy force a win
+var DefaultBasicWeights = BasicWeights{
+	MoveUp:         50,
+	MoveDownOne:    -20,
+	MoveDownTwo:    -100,
+	EdgeBuild:      -5,
+	CornerMove:     -20,
+	FewMoves:       -10,
+	FewBuilds:      -10,
+	BuildAbove:     -30,
+	BuildThree:     30,
+	BuildUp:        20,
+	BuildOnBlock:   30,
+	BuildNearEnemy: -10,
+	BuildNearBlock: 3,
+	NearTeammate:   -30,
+	StayHigh:       10,
+	TrapEnemy:      40,
+	CrampedWorker:  -15,
+	TrappedWorker:  -60,
+}
+
+// Params returns a pointer to every weight, always in the same order, so the weights can be treated as a vector
+func (w *BasicWeights) Params() []*int {
+	return []*int{
+		&w.MoveUp,
+		&w.MoveDownOne,
+		&w.MoveDownTwo,
+		&w.EdgeBuild,
+		&w.CornerMove,
+		&w.FewMoves,
+		&w.FewBuilds,
+		&w.BuildAbove,
+		&w.BuildThree,
+		&w.BuildUp,
+		&w.BuildOnBlock,
+		&w.BuildNearEnemy,
+		&w.BuildNearBlock,
+		&w.NearTeammate,
+		&w.StayHigh,
+		&w.TrapEnemy,
+		&w.CrampedWorker,
+		&w.TrappedWorker,
+	}
+}
+
+// LoadBasicWeights reads weights from a JSON file. Weights missing from the file keep their default value
+func LoadBasicWeights(path string) (BasicWeights, error) {
+	weights := DefaultBasicWeights
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return weights, err
+	}
+	err = json.Unmarshal(data, &weights)
+	return weights, err
+}
+
+// Save the weights to a JSON file
+func (w BasicWeights) Save(path string) error {
+	data, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
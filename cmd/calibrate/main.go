@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"santorini/bots"
+	santorini "santorini/pkg"
+	"santorini/pkg/arena"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Measure how often each difficulty level beats RandomBot, BasicBot and the level before it, to
+// check the win rates recorded in bots.Levels after a level or one of the bots changes
+func main() {
+	threadCount := flag.Int("threads", 10, "Number of threads to use")
+	games := flag.Int("games", 200, "Games against each opponent, every random opening is played twice so each bot moves first once")
+	seed := flag.Int64("seed", 1, "Seed for the first game, every other game n uses seed+n")
+	flag.Parse()
+
+	opponents := []string{"RandomBot", "BasicBot"}
+	wg := new(sync.WaitGroup)
+	sims := make(chan *santorini.Simulation)
+	completedSims := make(chan *santorini.Simulation)
+	for i := 0; i < *threadCount; i++ {
+		wg.Add(1)
+		go arena.Runner(wg, sims, completedSims)
+	}
+
+	fmt.Printf("%-10s %-20s %-20s %-20s\n", "level", "vs RandomBot", "vs BasicBot", "vs previous level")
+	var previous santorini.BotInitializer
+	for _, level := range bots.Levels {
+		bot, err := bots.Build(level.Spec)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		rates := make([]float64, len(opponents))
+		for i, name := range opponents {
+			opponent, err := bots.Build(name)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			rates[i] = winRate(bot, opponent, *games, *seed, sims, completedSims)
+		}
+		vsPrevious := "-"
+		if previous != nil {
+			vsPrevious = fmt.Sprintf("%.2f (recorded %.2f)", winRate(bot, previous, *games, *seed, sims, completedSims), level.VsPrevious)
+		}
+		fmt.Printf("%-10s %.2f (recorded %.2f)  %.2f (recorded %.2f)  %s\n", level.Name, rates[0], level.VsRandom, rates[1], level.VsBasic, vsPrevious)
+		previous = bot
+	}
+	close(sims)
+	wg.Wait()
+}
+
+// winRate plays bot against opponent from random openings and returns the share of games bot won.
+// Deterministic bots would play the same game over and over from a single opening
+func winRate(bot, opponent santorini.BotInitializer, games int, seed int64, sims, completedSims chan *santorini.Simulation) float64 {
+	go func() {
+		for i := 0; i < games; i++ {
+			opening := santorini.RandomPosition(rand.New(rand.NewSource(seed+int64(i/2))), 2, 0)
+			sims <- santorini.NewPositionSimulator(i, seed+int64(i), opening, logrus.StandardLogger(), arena.Seated(i, bot, opponent)...)
+		}
+	}()
+
+	wins := 0
+	for i := 0; i < games; i++ {
+		if arena.Winner(<-completedSims, 2) == 0 {
+			wins++
+		}
+	}
+	return float64(wins) / float64(games)
+}
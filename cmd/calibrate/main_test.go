@@ -0,0 +1,37 @@
+package main
+
+import (
+	"santorini/bots"
+	santorini "santorini/pkg"
+	"santorini/pkg/arena"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelsGetStronger(t *testing.T) {
+	if testing.Short() {
+		t.Skip("plays hundreds of games")
+	}
+	wg := new(sync.WaitGroup)
+	sims := make(chan *santorini.Simulation)
+	completedSims := make(chan *santorini.Simulation)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go arena.Runner(wg, sims, completedSims)
+	}
+	defer wg.Wait()
+	defer close(sims)
+
+	for i := 1; i < len(bots.Levels); i++ {
+		level, previous := bots.Levels[i], bots.Levels[i-1]
+		bot, err := bots.Build(level.Spec)
+		assert.NoError(t, err)
+		opponent, err := bots.Build(previous.Spec)
+		assert.NoError(t, err)
+		rate := winRate(bot, opponent, 40, 1, sims, completedSims)
+		assert.Greater(t, rate, 0.5, "%s won %.2f of its games against %s", level.Name, rate, previous.Name)
+		assert.Greater(t, level.VsPrevious, 0.5, "%s is recorded as no stronger than %s", level.Name, previous.Name)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"santorini/bots"
+	"santorini/pkg/engine"
+
+	"github.com/sirupsen/logrus"
+)
+
+// runEngine exposes one of the registered bots as an engine on in and out. The bot's
+// options are advertised during the handshake and setoption rebuilds the bot with the new value
+func runEngine(args []string, in io.Reader, out io.Writer) error {
+	flags := flag.NewFlagSet("engine", flag.ContinueOnError)
+	botSpec := flags.String("bot", "BasicBot", "Bot to expose as an engine, as Name:option=value,...")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	name, values, err := bots.ParseSpec(*botSpec)
+	if err != nil {
+		return err
+	}
+	entry, ok := bots.Lookup(name)
+	if !ok {
+		return fmt.Errorf("%s is not a known bot", name)
+	}
+	bot, err := entry.Build(values)
+	if err != nil {
+		return err
+	}
+
+	// Logs go to stderr so they never mix with the protocol
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	server := engine.NewServer(bot, logger)
+	for _, o := range entry.Options {
+		value, ok := values[o.Name]
+		if !ok {
+			value = o.Default
+		}
+		server.Options = append(server.Options, engine.Option{Name: o.Name, Type: o.Type, Default: value})
+	}
+	server.OnOption = func(name, value string) error {
+		next := map[string]string{name: value}
+		for k, v := range values {
+			if k != name {
+				next[k] = v
+			}
+		}
+		bot, err := entry.Build(next)
+		if err != nil {
+			return err
+		}
+		values, server.Bot = next, bot
+		return nil
+	}
+	return server.Serve(in, out)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"santorini/bots"
+	santorini "santorini/pkg"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// When the engine variable is set, the test binary runs as an engine so tests can launch it as a subprocess
+const engineEnv = "SANTORINI_TEST_ENGINE"
+
+func TestMain(m *testing.M) {
+	if bot := os.Getenv(engineEnv); bot != "" {
+		if err := runEngine([]string{"--bot", bot}, os.Stdin, os.Stdout); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestEngine(t *testing.T) {
+	in := strings.NewReader(strings.Join([]string{
+		"sep",
+		"isready",
+		"position startpos moves 1.1:c3d4",
+		"go movetime 100",
+		"position startpos moves 1.1:c3c1",
+		"isready",
+		"position notation 00000/00000/00300/00200/00000 1.1:b1,1.2:c4,2.1:a5,2.2:e5 1",
+		"go",
+		"position startpos moves 2.1:a3a4",
+		"quit",
+	}, "\n"))
+	out := &bytes.Buffer{}
+
+	assert.NoError(t, runEngine([]string{"--bot", "KyleBot"}, in, out))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, []string{"id name KyleBot", "sepok", "readyok"}, lines[:3])
+
+	// The first search plays for team 2
+	board := santorini.DefaultPosition(2)
+	board.PlayTurn(santorini.Turn{Team: 1, Worker: 1, MoveTo: board.GetTile(2, 2), Build: board.GetTile(3, 3)})
+	assert.True(t, strings.HasPrefix(lines[3], "bestmove 2."), lines[3])
+	turn, err := board.ParseTurn(strings.TrimPrefix(lines[3], "bestmove "))
+	assert.NoError(t, err)
+	assert.Contains(t, board.GetValidTurns(2), turn)
+
+	// Building two tiles away from the worker is illegal
+	assert.True(t, strings.HasPrefix(lines[4], "info string error: illegal turn"), lines[4])
+	assert.Equal(t, "readyok", lines[5])
+
+	// Team 1 can win by climbing from c4 to c3
+	assert.True(t, strings.HasPrefix(lines[6], "bestmove 1.2:c3"), lines[6])
+
+	// Team 2 cannot move first
+	assert.True(t, strings.HasPrefix(lines[7], "info string error: it is not team 2's turn"), lines[7])
+	assert.Len(t, lines, 8)
+}
+
+func TestEngineStop(t *testing.T) {
+	in := strings.NewReader(strings.Join([]string{
+		"go movetime 100",
+		"isready",
+		"go",
+		"isready",
+		"stop",
+		"go movetime soon",
+		"go",
+		"quit",
+	}, "\n"))
+	out := &bytes.Buffer{}
+
+	// Searching this deep would take far too long, so every search must have been cut short
+	start := time.Now()
+	assert.NoError(t, runEngine([]string{"--bot", "MinimaxBot:depth=9"}, in, out))
+	assert.Less(t, time.Since(start), 10*time.Second)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 6) {
+		board := santorini.DefaultPosition(2)
+		for _, line := range []string{lines[1], lines[3], lines[5]} {
+			turn, err := board.ParseTurn(strings.TrimPrefix(line, "bestmove "))
+			assert.NoError(t, err, line)
+			assert.Contains(t, board.GetValidTurns(1), turn)
+		}
+		// isready is answered while searching
+		assert.Equal(t, "readyok", lines[0])
+		assert.Equal(t, "readyok", lines[2])
+		assert.Equal(t, "info string error: invalid movetime \"soon\"", lines[4])
+	}
+}
+
+func TestExternalBot(t *testing.T) {
+	t.Setenv(engineEnv, "BasicBot")
+
+	engineBot := bots.NewExternalBot(os.Args[0])
+	sim := santorini.NewSimulator(0, logrus.StandardLogger(), engineBot, bots.NewRandomBot)
+	// The engine is only started once it has to play
+	assert.Equal(t, "ExternalBot("+os.Args[0]+")", sim.Teams[0].Name())
+	sim.Run()
+	assert.True(t, sim.Board.IsOver)
+	assert.NotZero(t, sim.Board.Victor)
+	assert.Equal(t, "BasicBot", sim.Teams[0].Name())
+}
+
+func TestEngineOptions(t *testing.T) {
+	in := strings.NewReader(strings.Join([]string{
+		"sep",
+		"setoption name depth value 2",
+		"setoption name depth value deep",
+		"setoption name color value blue",
+		"quit",
+	}, "\n"))
+	out := &bytes.Buffer{}
+
+	assert.NoError(t, runEngine([]string{"--bot", "MinimaxBot:depth=1"}, in, out))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, []string{
+		"id name MinimaxBot",
+		"option name depth type int default 1",
+		"option name eval type string default heuristic",
+		"option name threads type int default 1",
+		"option name deterministic type bool default false",
+		"sepok",
+		"info string error: option depth must be of type int, not \"deep\"",
+		"info string error: MinimaxBot has no option color",
+	}, lines)
+}
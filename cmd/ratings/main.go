@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"santorini/pkg/rating"
+	"text/tabwriter"
+)
+
+// Print the leaderboard of the bots rated by simulations and tournaments
+func main() {
+	path := flag.String("file", "ratings.json", "Ratings file written by simulate -ratings")
+	minGames := flag.Int("min-games", 0, "Only show bots that played at least this many games")
+	flag.Parse()
+
+	ledger, err := rating.Load(*path)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if len(ledger.Ratings) == 0 {
+		fmt.Printf("No bots have been rated in %s yet, run simulate -ratings %s to rate them\n", *path, *path)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tBot\tGlicko-2\t95% interval\tRD\tElo\tGames\tW-L-D")
+	rank := 0
+	for _, s := range ledger.Leaderboard() {
+		if s.Games() < *minGames {
+			continue
+		}
+		rank++
+		lo, hi := s.Interval()
+		fmt.Fprintf(w, "%d\t%s\t%.0f\t%.0f-%.0f\t%.0f\t%.0f\t%d\t%d-%d-%d\n",
+			rank, s.Name, s.Glicko, lo, hi, s.Deviation, s.Elo, s.Games(), s.Wins, s.Losses, s.Draws)
+	}
+	w.Flush()
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"math"
+	santorini "santorini/pkg"
+	"santorini/pkg/results"
+	"sort"
+	"strings"
+)
+
+// botStats are a bot's results across every game it played
+type botStats struct {
+	spec       string
+	games      int
+	wins       int
+	firstGames int // Games the bot went first
+	firstWins  int
+}
+
+// openingStats are the results of the games started from one position
+type openingStats struct {
+	position  string
+	games     int
+	firstWins int
+	wins      map[string]int // Wins by bot spec
+	played    map[string]int // Games by bot spec
+}
+
+// report is everything the report says about a set of games
+type report struct {
+	games      int
+	bots       []*botStats // Best win rate first
+	decided    int         // Games with a victor
+	firstWins  int
+	lengths    []int          // Turns of every game
+	reasons    map[string]int // Games by why they ended
+	botReasons map[string]map[string]int
+	climbs     [][]int // climbs[y][x] is how many games were won by climbing to the tile
+	openings   []*openingStats
+}
+
+func analyze(records []results.Record) *report {
+	r := &report{
+		reasons:    make(map[string]int),
+		botReasons: make(map[string]map[string]int),
+	}
+	bots := make(map[string]*botStats)
+	openings := make(map[string]*openingStats)
+	for _, rec := range records {
+		r.games++
+		r.lengths = append(r.lengths, rec.Turns)
+		for team, spec := range rec.Bots {
+			b, ok := bots[spec]
+			if !ok {
+				b = &botStats{spec: spec}
+				bots[spec] = b
+			}
+			b.games++
+			won := rec.Victor == team+1
+			if won {
+				b.wins++
+			}
+			if team == 0 {
+				b.firstGames++
+				if won {
+					b.firstWins++
+				}
+			}
+		}
+
+		o, ok := openings[rec.Opening]
+		if !ok {
+			o = &openingStats{position: rec.Opening, wins: make(map[string]int), played: make(map[string]int)}
+			openings[rec.Opening] = o
+		}
+		o.games++
+		for _, spec := range rec.Bots {
+			o.played[spec]++
+		}
+		if rec.Victor == 0 {
+			continue
+		}
+		r.decided++
+		o.wins[rec.Winner]++
+		if rec.Victor == 1 {
+			r.firstWins++
+			o.firstWins++
+		}
+		r.reasons[rec.Reason]++
+		if r.botReasons[rec.Winner] == nil {
+			r.botReasons[rec.Winner] = make(map[string]int)
+		}
+		r.botReasons[rec.Winner][rec.Reason]++
+		if rec.Reason == santorini.ReasonClimbed && len(rec.Moves) > 0 {
+			r.addClimb(rec.Moves[len(rec.Moves)-1], boardSize(rec.Opening))
+		}
+	}
+
+	for _, b := range bots {
+		r.bots = append(r.bots, b)
+	}
+	sort.Slice(r.bots, func(i, j int) bool {
+		a, b := r.bots[i], r.bots[j]
+		if rateOf(a.wins, a.games) != rateOf(b.wins, b.games) {
+			return rateOf(a.wins, a.games) > rateOf(b.wins, b.games)
+		}
+		return a.spec < b.spec
+	})
+	for _, o := range openings {
+		r.openings = append(r.openings, o)
+	}
+	sort.Slice(r.openings, func(i, j int) bool {
+		if r.openings[i].games != r.openings[j].games {
+			return r.openings[i].games > r.openings[j].games
+		}
+		return r.openings[i].position < r.openings[j].position
+	})
+	return r
+}
+
+// addClimb counts the tile the winning turn moved to, e.g. c3 in "1.1:c3d4"
+func (r *report) addClimb(turn string, size int) {
+	colon := strings.Index(turn, ":")
+	if colon < 0 || len(turn) < colon+3 {
+		return
+	}
+	square := turn[colon+1:]
+	end := 1
+	for end < len(square) && square[end] >= '0' && square[end] <= '9' {
+		end++
+	}
+	x, y, err := santorini.ParseSquare(square[:end])
+	if err != nil || x >= size || y >= size {
+		return
+	}
+	if r.climbs == nil {
+		r.climbs = make([][]int, size)
+		for i := range r.climbs {
+			r.climbs[i] = make([]int, size)
+		}
+	}
+	if y < len(r.climbs) && x < len(r.climbs[y]) {
+		r.climbs[y][x]++
+	}
+}
+
+// boardSize returns the size of the board in a position, 5 when it cannot be told
+func boardSize(position string) int {
+	if board, _, err := santorini.ParseNotation(position); err == nil {
+		return board.Size
+	}
+	return 5
+}
+
+func rateOf(wins, games int) float64 {
+	if games == 0 {
+		return 0
+	}
+	return float64(wins) / float64(games)
+}
+
+// wilson returns the 95% confidence interval of a win rate, which unlike the normal approximation
+// stays between 0 and 1 for few games or lopsided results
+func wilson(wins, games int) (float64, float64) {
+	if games == 0 {
+		return 0, 1
+	}
+	const z = 1.96
+	n := float64(games)
+	p := float64(wins) / n
+	center := (p + z*z/(2*n)) / (1 + z*z/n)
+	margin := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n)) / (1 + z*z/n)
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// bin is a bar of the game length histogram, of games lasting from lo to hi turns
+type bin struct {
+	lo, hi int
+	count  int
+}
+
+// histogram groups the lengths into at most bins bars of equal width
+func histogram(lengths []int, bins int) []bin {
+	if len(lengths) == 0 {
+		return nil
+	}
+	lo, hi := lengths[0], lengths[0]
+	for _, l := range lengths {
+		if l < lo {
+			lo = l
+		}
+		if l > hi {
+			hi = l
+		}
+	}
+	width := (hi - lo + bins) / bins
+	histogram := make([]bin, (hi-lo)/width+1)
+	for i := range histogram {
+		histogram[i].lo = lo + i*width
+		histogram[i].hi = lo + (i+1)*width - 1
+	}
+	for _, l := range lengths {
+		histogram[(l-lo)/width].count++
+	}
+	return histogram
+}
+
+// median returns the middle value
+func median(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]int, len(values))
+	copy(sorted, values)
+	sort.Ints(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return float64(sorted[mid-1]+sorted[mid]) / 2
+	}
+	return float64(sorted[mid])
+}
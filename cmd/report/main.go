@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	santorini "santorini/pkg"
+	"santorini/pkg/results"
+	"strings"
+)
+
+// Write a report of the games in result files written by simulate -results
+func main() {
+	out := flag.String("out", "", "Write the report to this file instead of standard output, as HTML if it ends in .html")
+	format := flag.String("format", "", "markdown or html (default from -out, otherwise markdown)")
+	maxOpenings := flag.Int("openings", 20, "Number of openings to list, the most played first")
+	flag.Usage = func() {
+		fmt.Printf("USAGE: %s [flags] results.jsonl [more.csv...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	var records []results.Record
+	for _, path := range flag.Args() {
+		read, err := results.Read(path)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		records = append(records, read...)
+	}
+	if len(records) == 0 {
+		fmt.Println("There are no games to report on")
+		os.Exit(1)
+	}
+
+	if *format == "" {
+		*format = "markdown"
+		if strings.HasSuffix(strings.ToLower(*out), ".html") {
+			*format = "html"
+		}
+	}
+	if *format != "markdown" && *format != "html" {
+		fmt.Printf("unknown format %q, use markdown or html\n", *format)
+		os.Exit(1)
+	}
+	w := bufio.NewWriter(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = bufio.NewWriter(f)
+	}
+	defer w.Flush()
+
+	const title = "Simulation report"
+	var r renderer = markdown{out: w}
+	if *format == "html" {
+		r = newHTMLPage(w, title)
+	}
+	r.heading(1, title)
+	write(r, analyze(records), flag.Args(), *maxOpenings)
+	r.close()
+}
+
+// write every section of the report
+func write(r renderer, rep *report, files []string, maxOpenings int) {
+	r.paragraph(fmt.Sprintf("%d games from %s.", rep.games, strings.Join(files, ", ")))
+
+	r.heading(2, "Win rates")
+	rows := make([][]string, len(rep.bots))
+	for i, b := range rep.bots {
+		rows[i] = []string{
+			b.spec,
+			fmt.Sprint(b.games),
+			fmt.Sprint(b.wins),
+			percent(rateOf(b.wins, b.games)),
+			interval(b.wins, b.games),
+			fmt.Sprintf("%s of %d", percent(rateOf(b.firstWins, b.firstGames)), b.firstGames),
+			fmt.Sprintf("%s of %d", percent(rateOf(b.wins-b.firstWins, b.games-b.firstGames)), b.games-b.firstGames),
+		}
+	}
+	r.table([]string{"Bot", "Games", "Wins", "Win rate", "95% interval", "Going first", "Going later"}, rows)
+
+	r.heading(2, "First player advantage")
+	r.paragraph(fmt.Sprintf("The bot that went first won %d of %d games, %s with a 95%% interval of %s.",
+		rep.firstWins, rep.decided, percent(rateOf(rep.firstWins, rep.decided)), interval(rep.firstWins, rep.decided)))
+
+	r.heading(2, "Game length")
+	lo, hi := rep.lengths[0], rep.lengths[0]
+	sum := 0
+	for _, l := range rep.lengths {
+		sum += l
+		if l < lo {
+			lo = l
+		}
+		if l > hi {
+			hi = l
+		}
+	}
+	r.paragraph(fmt.Sprintf("Games lasted %.1f turns on average, with a median of %g, from %d to %d turns.",
+		float64(sum)/float64(len(rep.lengths)), median(rep.lengths), lo, hi))
+	bins := histogram(rep.lengths, 15)
+	labels := make([]string, len(bins))
+	counts := make([]int, len(bins))
+	for i, b := range bins {
+		labels[i] = fmt.Sprint(b.lo)
+		if b.hi > b.lo {
+			labels[i] = fmt.Sprintf("%d-%d", b.lo, b.hi)
+		}
+		counts[i] = b.count
+	}
+	r.bars(labels, counts)
+
+	r.heading(2, "How games were won")
+	reasons := []string{santorini.ReasonClimbed, santorini.ReasonTrapped, santorini.ReasonEliminated, santorini.ReasonForfeit}
+	headers := []string{"Bot"}
+	for _, reason := range reasons {
+		headers = append(headers, reason)
+	}
+	rows = nil
+	for _, b := range rep.bots {
+		row := []string{b.spec}
+		for _, reason := range reasons {
+			row = append(row, fmt.Sprint(rep.botReasons[b.spec][reason]))
+		}
+		rows = append(rows, row)
+	}
+	total := []string{"All bots"}
+	for _, reason := range reasons {
+		total = append(total, fmt.Sprintf("%d (%s)", rep.reasons[reason], percent(rateOf(rep.reasons[reason], rep.decided))))
+	}
+	r.table(headers, append(rows, total))
+
+	r.heading(2, "Winning climbs")
+	if rep.climbs == nil {
+		r.paragraph("No game was won by climbing.")
+	} else {
+		r.paragraph("The tiles winning workers climbed to, laid out like the board.")
+		columns := make([]string, len(rep.climbs))
+		rowNames := make([]string, len(rep.climbs))
+		for i := range rep.climbs {
+			columns[i] = string(rune('a' + i))
+			rowNames[i] = fmt.Sprint(i + 1)
+		}
+		r.heatmap(columns, rowNames, rep.climbs)
+	}
+
+	r.heading(2, "Openings")
+	headers = []string{"Opening", "Games", "First player wins"}
+	for _, b := range rep.bots {
+		headers = append(headers, b.spec)
+	}
+	rows = nil
+	for i, o := range rep.openings {
+		if i == maxOpenings {
+			break
+		}
+		position := o.position
+		if position == "" {
+			position = "Not recorded"
+		}
+		row := []string{position, fmt.Sprint(o.games), percent(rateOf(o.firstWins, o.games))}
+		for _, b := range rep.bots {
+			row = append(row, fmt.Sprintf("%d of %d", o.wins[b.spec], o.played[b.spec]))
+		}
+		rows = append(rows, row)
+	}
+	r.table(headers, rows)
+	if len(rep.openings) > maxOpenings {
+		r.paragraph(fmt.Sprintf("%d more openings are not listed.", len(rep.openings)-maxOpenings))
+	}
+}
+
+func percent(rate float64) string {
+	return fmt.Sprintf("%.1f%%", 100*rate)
+}
+
+func interval(wins, games int) string {
+	lo, hi := wilson(wins, games)
+	return fmt.Sprintf("%.1f%% to %.1f%%", 100*lo, 100*hi)
+}
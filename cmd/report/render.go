@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// renderer writes the parts of a report in one format
+type renderer interface {
+	heading(level int, text string)
+	paragraph(text string)
+	table(headers []string, rows [][]string)
+	// bars draws a horizontal bar for each label, sized by its count
+	bars(labels []string, counts []int)
+	// heatmap shades every cell by its count, counts[y][x] is in row y and column x
+	heatmap(columns, rows []string, counts [][]int)
+	close()
+}
+
+// maxCount returns the largest count, at least 1 so it can be divided by
+func maxCount(counts []int) int {
+	most := 1
+	for _, c := range counts {
+		if c > most {
+			most = c
+		}
+	}
+	return most
+}
+
+// markdown renders plain text that reads well before it is rendered
+type markdown struct {
+	out io.Writer
+}
+
+func (m markdown) heading(level int, text string) {
+	fmt.Fprintf(m.out, "%s %s\n\n", strings.Repeat("#", level), text)
+}
+
+func (m markdown) paragraph(text string) {
+	fmt.Fprintf(m.out, "%s\n\n", text)
+}
+
+func (m markdown) table(headers []string, rows [][]string) {
+	fmt.Fprintf(m.out, "| %s |\n", strings.Join(headers, " | "))
+	fmt.Fprintf(m.out, "|%s\n", strings.Repeat(" --- |", len(headers)))
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = strings.ReplaceAll(cell, "|", "\\|")
+		}
+		fmt.Fprintf(m.out, "| %s |\n", strings.Join(cells, " | "))
+	}
+	fmt.Fprintln(m.out)
+}
+
+func (m markdown) bars(labels []string, counts []int) {
+	const width = 40
+	most := maxCount(counts)
+	fmt.Fprintln(m.out, "```")
+	for i, label := range labels {
+		fmt.Fprintf(m.out, "%8s %-*s %d\n", label, width, strings.Repeat("█", counts[i]*width/most), counts[i])
+	}
+	fmt.Fprint(m.out, "```\n\n")
+}
+
+func (m markdown) heatmap(columns, rows []string, counts [][]int) {
+	cells := make([][]string, len(rows))
+	for y, row := range rows {
+		cells[y] = append([]string{row}, make([]string, len(columns))...)
+		for x := range columns {
+			cells[y][x+1] = fmt.Sprint(counts[y][x])
+		}
+	}
+	m.table(append([]string{""}, columns...), cells)
+}
+
+func (m markdown) close() {}
+
+// htmlPage renders a standalone page with its styles inline, so it can be opened offline
+type htmlPage struct {
+	out io.Writer
+}
+
+func newHTMLPage(out io.Writer, title string) htmlPage {
+	fmt.Fprintf(out, `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 60em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.bar { background: #4a7bb7; height: 1em; }
+.heatmap td { width: 2.5em; height: 2.5em; text-align: center; }
+</style>
+</head>
+<body>
+`, html.EscapeString(title))
+	return htmlPage{out: out}
+}
+
+func (h htmlPage) heading(level int, text string) {
+	fmt.Fprintf(h.out, "<h%d>%s</h%d>\n", level, html.EscapeString(text), level)
+}
+
+func (h htmlPage) paragraph(text string) {
+	fmt.Fprintf(h.out, "<p>%s</p>\n", html.EscapeString(text))
+}
+
+func (h htmlPage) table(headers []string, rows [][]string) {
+	fmt.Fprintln(h.out, "<table>")
+	fmt.Fprint(h.out, "<tr>")
+	for _, header := range headers {
+		fmt.Fprintf(h.out, "<th>%s</th>", html.EscapeString(header))
+	}
+	fmt.Fprintln(h.out, "</tr>")
+	for _, row := range rows {
+		fmt.Fprint(h.out, "<tr>")
+		for _, cell := range row {
+			fmt.Fprintf(h.out, "<td>%s</td>", html.EscapeString(cell))
+		}
+		fmt.Fprintln(h.out, "</tr>")
+	}
+	fmt.Fprintln(h.out, "</table>")
+}
+
+func (h htmlPage) bars(labels []string, counts []int) {
+	most := maxCount(counts)
+	fmt.Fprintln(h.out, "<table>")
+	for i, label := range labels {
+		fmt.Fprintf(h.out, "<tr><td>%s</td><td style=\"width: 25em; text-align: left\"><div class=\"bar\" style=\"width: %.1f%%\"></div></td><td>%d</td></tr>\n",
+			html.EscapeString(label), 100*float64(counts[i])/float64(most), counts[i])
+	}
+	fmt.Fprintln(h.out, "</table>")
+}
+
+func (h htmlPage) heatmap(columns, rows []string, counts [][]int) {
+	most := 1
+	for _, row := range counts {
+		if m := maxCount(row); m > most {
+			most = m
+		}
+	}
+	fmt.Fprintln(h.out, `<table class="heatmap">`)
+	fmt.Fprint(h.out, "<tr><th></th>")
+	for _, column := range columns {
+		fmt.Fprintf(h.out, "<th>%s</th>", html.EscapeString(column))
+	}
+	fmt.Fprintln(h.out, "</tr>")
+	for y, row := range rows {
+		fmt.Fprintf(h.out, "<tr><th>%s</th>", html.EscapeString(row))
+		for x := range columns {
+			fmt.Fprintf(h.out, "<td style=\"background: rgba(200, 60, 40, %.2f)\">%d</td>", float64(counts[y][x])/float64(most), counts[y][x])
+		}
+		fmt.Fprintln(h.out, "</tr>")
+	}
+	fmt.Fprintln(h.out, "</table>")
+}
+
+func (h htmlPage) close() {
+	fmt.Fprintln(h.out, "</body>\n</html>")
+}
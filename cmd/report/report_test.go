@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	santorini "santorini/pkg"
+	"santorini/pkg/results"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyze(t *testing.T) {
+	const opening = "00000/00000/00000/00000/00000 1.1:a1,1.2:b1,2.1:a2,2.2:b2 1"
+	r := analyze([]results.Record{
+		{Bots: []string{"A", "B"}, Victor: 1, Winner: "A", Reason: santorini.ReasonClimbed, Turns: 10, Opening: opening, Moves: []string{"1.1:c3d4"}},
+		{Bots: []string{"B", "A"}, Victor: 1, Winner: "B", Reason: santorini.ReasonTrapped, Turns: 20, Opening: opening},
+		{Bots: []string{"A", "B"}, Victor: 2, Winner: "B", Reason: santorini.ReasonClimbed, Turns: 30, Opening: "other", Moves: []string{"2.1:e5d5"}},
+		{Bots: []string{"B", "A"}, Turns: 40, Opening: opening},
+	})
+	assert.Equal(t, 4, r.games)
+	assert.Equal(t, 3, r.decided)
+	assert.Equal(t, 2, r.firstWins)
+	assert.Equal(t, "B", r.bots[0].spec)
+	assert.Equal(t, botStats{spec: "B", games: 4, wins: 2, firstGames: 2, firstWins: 1}, *r.bots[0])
+	assert.Equal(t, map[string]int{santorini.ReasonClimbed: 2, santorini.ReasonTrapped: 1}, r.reasons)
+	assert.Equal(t, 1, r.botReasons["B"][santorini.ReasonTrapped])
+	assert.Equal(t, 1, r.climbs[2][2])
+	assert.Equal(t, 1, r.climbs[4][4])
+
+	assert.Equal(t, opening, r.openings[0].position)
+	assert.Equal(t, 3, r.openings[0].games)
+	assert.Equal(t, 2, r.openings[0].firstWins)
+	assert.Equal(t, map[string]int{"A": 1, "B": 1}, r.openings[0].wins)
+
+	out := new(bytes.Buffer)
+	write(markdown{out: out}, r, []string{"results.jsonl"}, 1)
+	assert.Contains(t, out.String(), "| B | 4 | 2 | 50.0% | 15.0% to 85.0% | 50.0% of 2 | 50.0% of 2 |")
+	assert.Contains(t, out.String(), "The bot that went first won 2 of 3 games")
+	assert.Contains(t, out.String(), "1 more openings are not listed.")
+}
+
+func TestWilson(t *testing.T) {
+	lo, hi := wilson(50, 100)
+	assert.InDelta(t, 0.404, lo, 0.001)
+	assert.InDelta(t, 0.596, hi, 0.001)
+	lo, hi = wilson(0, 10)
+	assert.Equal(t, 0.0, lo)
+	assert.InDelta(t, 0.278, hi, 0.001)
+}
+
+func TestHistogram(t *testing.T) {
+	assert.Equal(t, []bin{{lo: 5, hi: 5, count: 2}, {lo: 6, hi: 6, count: 0}, {lo: 7, hi: 7, count: 1}}, histogram([]int{5, 7, 5}, 10))
+	assert.Equal(t, []bin{{lo: 0, hi: 4, count: 2}, {lo: 5, hi: 9, count: 1}}, histogram([]int{0, 4, 9}, 2))
+	assert.Equal(t, 2.5, median([]int{4, 1, 3, 2}))
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
 	"santorini/bots"
@@ -18,8 +19,48 @@ type TurnSelector interface {
 	SelectTurn() santorini.Turn
 }
 
+func levelNames() string {
+	names := make([]string, len(bots.Levels))
+	for i, level := range bots.Levels {
+		names[i] = level.Name
+	}
+	return strings.Join(names, ", ")
+}
+
 func main() {
-	game := ui.NewGame(1, bots.NewRandomBot)
+	if len(os.Args) > 1 && os.Args[1] == "engine" {
+		if err := runEngine(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	botSpec := flag.String("bot", "RandomBot", "Bot to play against as Name:option=value,..., or exec:\"command args\" for an external engine")
+	level := flag.String("level", "", "Difficulty to play against instead of a bot: "+levelNames())
+	advisor := flag.String("hint-bot", "MinimaxBot:depth=2", "Bot that suggests turns when you type 'hint', empty to turn hints off")
+	blunderWarning := flag.Bool("blunder-warning", false, "Ask before playing a turn that lets the enemy climb to level 3")
+	flag.Parse()
+
+	var bot santorini.BotInitializer
+	var err error
+	if *level != "" {
+		bot, err = bots.FindLevel(*level)
+	} else {
+		bot, err = bots.Build(*botSpec)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	game := ui.NewGame(1, bot)
+	game.BlunderWarning = *blunderWarning
+	if *advisor != "" {
+		if game.Advisor, err = bots.Build(*advisor); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
 	game.Run()
 
 }
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	santorini "santorini/pkg"
+	"santorini/pkg/results"
+	"strings"
+	"time"
+)
+
+// checkpoint is the progress of a simulation, saved so an interrupted run can be resumed. Game n
+// is always played with seed+n, so the games still to play are known from the completed ones.
+// The file is JSON Lines, the run's settings followed by every completed game, so each save only
+// appends the games completed since the last one
+type checkpoint struct {
+	Bots          []string `json:"bots"`
+	Seed          int64    `json:"seed"`
+	Games         int      `json:"games"`
+	Openings      string   `json:"openings,omitempty"`
+	OpeningBuilds int      `json:"opening_builds,omitempty"`
+
+	completedGames []savedGame
+	file           *os.File // Open for appending once the settings have been written
+	saved          int      // Games written to file
+}
+
+// savedGame is a completed game. The record only keeps the error of a forfeit, so they are saved in full
+type savedGame struct {
+	Record  results.Record `json:"record"`
+	Crashes []savedCrash   `json:"crashes,omitempty"`
+}
+
+// savedCrash is a santorini.Crash, with the error as text
+type savedCrash struct {
+	Team     int    `json:"team"`
+	Bot      string `json:"bot"`
+	Err      string `json:"error"`
+	Stack    string `json:"stack,omitempty"`
+	Position string `json:"position"`
+}
+
+// newCheckpoint starts the checkpoint of a run, so it can be resumed even if no game has finished
+func newCheckpoint(bots []string, seed int64, games int, openings string, openingBuilds int) *checkpoint {
+	return &checkpoint{
+		Bots:          bots,
+		Seed:          seed,
+		Games:         games,
+		Openings:      openings,
+		OpeningBuilds: openingBuilds,
+	}
+}
+
+// loadCheckpoint reads the checkpoint of an earlier run. A last game cut off by an interruption
+// while it was being saved is left out, it will be played again
+func loadCheckpoint(path string) (*checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	c := &checkpoint{}
+	if err := json.Unmarshal([]byte(lines[0]), c); err != nil {
+		return nil, fmt.Errorf("%s is not a checkpoint: %w", path, err)
+	}
+	for i, line := range lines[1:] {
+		var game savedGame
+		if err := json.Unmarshal([]byte(line), &game); err != nil {
+			if i == len(lines)-2 {
+				break
+			}
+			return nil, fmt.Errorf("%s:%d: %w", path, i+2, err)
+		}
+		c.completedGames = append(c.completedGames, game)
+	}
+	return c, nil
+}
+
+// add a completed game, to be written by the next save
+func (c *checkpoint) add(sim *santorini.Simulation, specs []string) {
+	game := savedGame{Record: newRecord(sim, specs)}
+	for _, crash := range sim.Crashes {
+		game.Crashes = append(game.Crashes, savedCrash{Team: crash.Team, Bot: crash.Bot, Err: crash.Err.Error(), Stack: crash.Stack, Position: crash.Position})
+	}
+	c.completedGames = append(c.completedGames, game)
+}
+
+// save appends the games added since the last save. The first save writes the whole checkpoint to a
+// temporary file, so an interruption never leaves a checkpoint without its settings
+func (c *checkpoint) save(path string) error {
+	if c.file == nil {
+		data, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+		for _, game := range c.completedGames {
+			line, err := json.Marshal(game)
+			if err != nil {
+				return err
+			}
+			data = append(append(data, line...), '\n')
+		}
+		if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+			return err
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			return err
+		}
+		if c.file, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644); err != nil {
+			return err
+		}
+		c.saved = len(c.completedGames)
+		return nil
+	}
+
+	var data []byte
+	for _, game := range c.completedGames[c.saved:] {
+		line, err := json.Marshal(game)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	if _, err := c.file.Write(data); err != nil {
+		return err
+	}
+	c.saved = len(c.completedGames)
+	return nil
+}
+
+// close the checkpoint file, if it has been saved
+func (c *checkpoint) close() error {
+	if c.file == nil {
+		return nil
+	}
+	return c.file.Close()
+}
+
+// completed returns the numbers of the games that have been played
+func (c *checkpoint) completed() map[int]bool {
+	completed := make(map[int]bool, len(c.completedGames))
+	for _, game := range c.completedGames {
+		completed[game.Record.Number] = true
+	}
+	return completed
+}
+
+// replayRecord plays the record's moves from its opening, returning the opening and the board the
+// game ended on. Teams that are passed over were knocked out, so their workers are taken off the board
+func replayRecord(r results.Record) (*santorini.Board, *santorini.Board, error) {
+	start, next, err := santorini.ParseNotation(r.Opening)
+	if err != nil {
+		return nil, nil, err
+	}
+	board := start.Clone()
+	for i, notation := range r.Moves {
+		turn, err := board.ParseTurn(notation)
+		if err != nil {
+			return nil, nil, fmt.Errorf("game %d turn %d: %w", r.Number, i+1, err)
+		}
+		for next != turn.Team {
+			if len(r.Bots) == 2 || !board.Teams[turn.Team] {
+				return nil, nil, fmt.Errorf("game %d turn %d: team %d moved out of turn", r.Number, i+1, turn.Team)
+			}
+			board.Eliminate(next)
+			next = board.NextTeam(next)
+		}
+		board.PlayTurn(turn)
+		next = board.NextTeam(turn.Team)
+	}
+	return start, board, nil
+}
+
+// recordedBot stands in for a bot of a game restored from a checkpoint, which can only give its spec
+type recordedBot string
+
+func (b recordedBot) Name() string {
+	return string(b)
+}
+
+func (b recordedBot) SelectTurn() *santorini.Turn {
+	return nil
+}
+
+func (b recordedBot) IsDeterministic() bool {
+	return false
+}
+
+// recordedSimulation rebuilds a completed game from the checkpoint, so it can be counted and printed
+// like a game played in this run. The bots' explanations are not saved
+func recordedSimulation(game savedGame) (*santorini.Simulation, error) {
+	r := game.Record
+	start, board, err := replayRecord(r)
+	if err != nil {
+		return nil, err
+	}
+	sim := &santorini.Simulation{
+		Number:     r.Number,
+		Seed:       r.Seed,
+		Start:      start,
+		Board:      board,
+		Reason:     r.Reason,
+		TurnTimes:  make([]time.Duration, len(r.TurnTimeMS)),
+		Eliminated: eliminated(r),
+	}
+	for _, spec := range r.Bots {
+		sim.Teams = append(sim.Teams, recordedBot(spec))
+	}
+	// Teams knocked out once the others had moved for the last time are still on the board
+	if len(r.Bots) > 2 {
+		for _, team := range sim.Eliminated {
+			if sim.Board.Teams[team] {
+				sim.Board.Eliminate(team)
+			}
+		}
+	}
+	sim.Board.Victor = r.Victor
+	for i, ms := range r.TurnTimeMS {
+		sim.TurnTimes[i] = time.Duration(ms * float64(time.Millisecond))
+	}
+	for _, crash := range game.Crashes {
+		sim.Crashes = append(sim.Crashes, santorini.Crash{Team: crash.Team, Bot: crash.Bot, Err: errors.New(crash.Err), Stack: crash.Stack, Position: crash.Position})
+	}
+	return sim, nil
+}
+
+// eliminated returns the teams knocked out of the recorded game in the order they went out, from the
+// places they finished in. Teams still playing when a worker climbed to win share 2nd place, every
+// other way of winning knocks out all the other teams
+func eliminated(r results.Record) []int {
+	var teams []int
+	for place := len(r.Places); place >= 2; place-- {
+		if place == 2 && r.Reason == santorini.ReasonClimbed {
+			break
+		}
+		for team, p := range r.Places {
+			if p == place {
+				teams = append(teams, team+1)
+			}
+		}
+	}
+	return teams
+}
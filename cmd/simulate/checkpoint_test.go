@@ -0,0 +1,130 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"santorini/bots"
+	santorini "santorini/pkg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	opts := &options{seed: 3}
+	specs := []string{"KyleBot", "BasicBot"}
+	initializers := []santorini.BotInitializer{bots.NewKyleBot, bots.NewBasicBot}
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	newStats := func() *overallstats {
+		return &overallstats{
+			wins:            make([]int, 2),
+			specs:           specs,
+			book:            bots.NewBook(),
+			profile:         newProfiler(specs),
+			bookPlies:       4,
+			checkpoint:      newCheckpoint(specs, opts.seed, 2, "", 0),
+			checkpointPath:  path,
+			checkpointEvery: 2,
+		}
+	}
+
+	stats := newStats()
+	sims := make([]*santorini.Simulation, 2)
+	for i := range sims {
+		sims[i] = newSimulation(opts, i, initializers)
+		sims[i].Run()
+		stats.update(sims[i])
+	}
+
+	c, err := loadCheckpoint(path)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]bool{0: true, 1: true}, c.completed())
+
+	// The moves of each game can be played again from its record
+	start, end, err := replayRecord(c.completedGames[1].Record)
+	assert.NoError(t, err)
+	assert.Equal(t, sims[1].Start.Notation(1), start.Notation(1))
+	assert.Equal(t, sims[1].Board.Notation(1), end.Notation(1))
+
+	resumed := newStats()
+	assert.NoError(t, resumed.restore(c))
+	assert.Equal(t, stats.wins, resumed.wins)
+	assert.Equal(t, stats.sumRounds, resumed.sumRounds)
+	assert.Equal(t, stats.book.Entries, resumed.book.Entries)
+	assert.NotEmpty(t, stats.losses)
+	assert.Len(t, resumed.losses, len(stats.losses))
+	for i, loss := range resumed.losses {
+		assert.Equal(t, stats.losses[i].Number, loss.Number)
+		assert.Equal(t, stats.losses[i].Board.Victor, loss.Board.Victor)
+		assert.Len(t, loss.Board.Moves, len(stats.losses[i].Board.Moves))
+	}
+	assert.Len(t, resumed.profile.times[0], len(stats.profile.times[0]))
+	assert.InDelta(t, stats.profile.times[1][0], resumed.profile.times[1][0], 0.001)
+
+	// Forfeits keep where the bot went wrong
+	c.completedGames[1].Crashes = []savedCrash{{Team: 2, Bot: "BasicBot", Err: "illegal turn", Position: c.completedGames[1].Record.Opening}}
+	resumed = newStats()
+	assert.NoError(t, resumed.restore(c))
+	assert.Len(t, resumed.crashes, 1)
+	assert.Equal(t, 1, resumed.crashes[0].Number)
+	assert.Equal(t, "illegal turn", resumed.crashes[0].Crashes[0].Err.Error())
+	assert.Equal(t, c.completedGames[1].Record.Opening, resumed.crashes[0].Crashes[0].Position)
+}
+
+func TestCheckpointBeforeAnyGame(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	assert.NoError(t, newCheckpoint([]string{"KyleBot", "BasicBot"}, 3, 2, "", 0).save(path))
+	loaded, err := loadCheckpoint(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"KyleBot", "BasicBot"}, loaded.Bots)
+	assert.Equal(t, 2, loaded.Games)
+	assert.Empty(t, loaded.completed())
+}
+
+func TestCheckpointAppends(t *testing.T) {
+	opts := &options{seed: 5}
+	specs := []string{"RandomBot", "RandomBot"}
+	initializers := []santorini.BotInitializer{bots.NewRandomBot, bots.NewRandomBot}
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	c := newCheckpoint(specs, opts.seed, 3, "", 0)
+	defer c.close()
+	for i := 0; i < 3; i++ {
+		sim := newSimulation(opts, i, initializers)
+		sim.Run()
+		c.add(sim, specs)
+		assert.NoError(t, c.save(path))
+	}
+	loaded, err := loadCheckpoint(path)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]bool{0: true, 1: true, 2: true}, loaded.completed())
+
+	// A game cut off while it was being saved is played again
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, data[:len(data)-10], 0644))
+	loaded, err = loadCheckpoint(path)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]bool{0: true, 1: true}, loaded.completed())
+}
+
+func TestCheckpointEliminations(t *testing.T) {
+	opts := &options{seed: 1}
+	specs := []string{"RandomBot", "RandomBot", "RandomBot"}
+	initializers := []santorini.BotInitializer{bots.NewRandomBot, bots.NewRandomBot, bots.NewRandomBot}
+	played := 0
+	for i := 0; i < 200 && played < 5; i++ {
+		sim := newSimulation(opts, i, initializers)
+		sim.Run()
+		if len(sim.Eliminated) == 0 {
+			continue
+		}
+		played++
+
+		restored, err := recordedSimulation(savedGame{Record: newRecord(sim, specs)})
+		assert.NoError(t, err)
+		assert.Equal(t, sim.Eliminated, restored.Eliminated)
+		assert.Equal(t, sim.Places(), restored.Places())
+		assert.Equal(t, sim.Board.Notation(1), restored.Board.Notation(1))
+	}
+	assert.Equal(t, 5, played)
+}
@@ -1,111 +1,524 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"runtime/pprof"
 	"santorini/bots"
 	santorini "santorini/pkg"
+	"santorini/pkg/arena"
+	"santorini/pkg/rating"
+	"santorini/pkg/results"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"text/tabwriter"
+	"time"
 
 	"github.com/schollz/progressbar/v3"
 	"github.com/sirupsen/logrus"
 )
 
-var knownbots = []santorini.BotInitializer{bots.NewBasicBot, bots.NewKyleBot, bots.NewRandomBot}
-
 func listBots() {
-	for i, b := range knownbots {
-		bot := b(0, &santorini.Board{}, nil)
-		fmt.Printf("%d. %s\n", i, bot.Name())
+	fmt.Println("Bots are written as Name:option=value,...")
+	for _, entry := range bots.Registered() {
+		fmt.Printf("  %s - %s\n", entry.Name, entry.Description)
+		for _, o := range entry.Options {
+			fmt.Printf("      %s (%s, default %q) %s\n", o.Name, o.Type, o.Default, o.Description)
+		}
+	}
+	fmt.Println("External engines can be used with exec:\"command args\"")
+}
+
+// cleanups are run by exit, since os.Exit skips deferred calls
+var cleanups []func()
+
+// atExit runs f when main returns or the program exits, in the reverse order they were added
+func atExit(f func()) {
+	cleanups = append(cleanups, f)
+}
+
+// cleanup runs the functions added with atExit
+func cleanup() {
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+	cleanups = nil
+}
+
+// exit flushes the results, ratings and profiles before exiting
+func exit(code int) {
+	cleanup()
+	os.Exit(code)
+}
+
+// closeBot shuts down bots that hold on to resources, such as external engines
+func closeBot(bot santorini.TurnSelector) {
+	if closer, ok := bot.(io.Closer); ok {
+		closer.Close()
 	}
 }
 
 type options struct {
-	threadCount int `help:"Number of threads to use"`
+	threadCount int
 	simCount    int
+	seed        int64 // Game n is played with seed+n
+	game        int   // Only replay this game
+	bookPath    string
+	bookPlies   int
+	explain     bool // Ask bots to explain their turns
+	losses      int  // Print this many of the first bot's losses
+	tournament  string
+	swissRounds int
+	ratingsPath string
+	sprt        bool
+	elo0, elo1  float64
+	alpha, beta float64
+	resultsPath string
+	turnTimeout time.Duration
+	openings    *openings // nil to start every game from the default position
+	allocs      bool      // Count the memory bots allocate
+	profileBot  int       // Bot whose turns can be focused on in profiles, from 1
+	// Save progress to the checkpoint every checkpointEvery games
+	checkpointPath  string
+	checkpointEvery int
 }
 
 type overallstats struct {
-	bot1Wins int
-	bot2Wins int
+	wins     []int   // Wins of each bot, in the order they were given
+	seatWins []int   // Wins of each team, team 1 goes first
+	places   [][]int // places[bot][p] is how many games the bot finished in place p+1
 	// Calculate average round count
-	sumRounds  int
-	loseBoards []*santorini.Board
-	pb         *progressbar.ProgressBar
+	sumRounds int
+	losses    []*santorini.Simulation
+	crashes   []*santorini.Simulation // Games a bot forfeited
+	profile   *profiler
+	pb        *progressbar.ProgressBar
+	// Collects the opening turns of the winners
+	book      *bots.Book
+	bookPlies int
+	// Rates the bots by their canonical specs
+	ratings *rating.Ledger
+	keys    []string
+	// Exports every game, with the bots' specs
+	results *results.Writer
+	specs   []string
+	// Saves the completed games so the run can be resumed
+	checkpoint      *checkpoint
+	checkpointPath  string
+	checkpointEvery int
 }
 
 func (stats *overallstats) update(sim *santorini.Simulation) {
-	// The first bot can be team 1 or team 2 depending on the round number
-	if sim.Board.Victor == sim.Number%2+1 {
-		stats.bot1Wins++
-	} else {
-		stats.bot2Wins++
-		// Keep track of the losses
-		stats.loseBoards = append(stats.loseBoards, sim.Board)
+	places := stats.tally(sim.Number, sim.Board.Victor, sim.Places())
+	// Which team each bot plays rotates with the game number
+	if winner := arena.Winner(sim, len(stats.wins)); winner != 0 {
+		// Keep track of the first bot's losses
+		stats.losses = append(stats.losses, sim)
+	}
+	stats.sumRounds += len(sim.Board.Moves) / len(stats.wins)
+	recordResult(stats.results, sim, stats.specs)
+	stats.profile.record(sim, positions(len(stats.wins)))
+	if len(sim.Crashes) > 0 {
+		stats.crashes = append(stats.crashes, sim)
 	}
-	stats.sumRounds += len(sim.Board.Moves) / 2
-	if stats.pb != nil {
-		stats.pb.Describe(fmt.Sprintf("%03d / %03d", stats.bot1Wins, stats.bot2Wins))
-		stats.pb.Add(1)
+	stats.rate(places)
+	if stats.book != nil {
+		stats.book.AddGame(sim.Start, sim.Board.Moves, sim.Board.Victor, stats.bookPlies)
 	}
+	if stats.checkpoint != nil {
+		stats.checkpoint.add(sim, stats.specs)
+		if len(stats.checkpoint.completedGames)%stats.checkpointEvery == 0 {
+			stats.saveCheckpoint()
+		}
+	}
+	stats.describe(1)
 }
 
-func main() {
-	if len(os.Args) < 3 {
-		fmt.Println("Chose two bots to simulate. Bots will alternate going first. Deterministic bots will only run 1 game each.")
-		fmt.Printf("USAGE: %s bot1 bot2 [numRounds]\n", os.Args[0])
-		listBots()
-		os.Exit(1)
+// tally counts the winner of game number n and the place every team finished in, returning the
+// places by bot
+func (stats *overallstats) tally(n, victor int, places []int) []int {
+	bots := len(stats.wins)
+	if stats.places == nil {
+		stats.seatWins = make([]int, bots)
+		stats.places = make([][]int, bots)
+		for i := range stats.places {
+			stats.places[i] = make([]int, bots)
+		}
 	}
+	stats.wins[arena.Seat(victor-1, n, bots)]++
+	stats.seatWins[victor-1]++
+	byBot := make([]int, bots)
+	for team, place := range places {
+		bot := arena.Seat(team, n, bots)
+		byBot[bot] = place
+		stats.places[bot][place-1]++
+	}
+	return byBot
+}
 
-	var bot1 santorini.BotInitializer
-	var bot2 santorini.BotInitializer
-	for _, b := range knownbots {
-		bot := b(0, &santorini.Board{}, nil)
-		if bot.Name() == os.Args[1] {
-			bot1 = b
+// rate every pair of bots at the table, the bot that finished in the better place won and bots
+// that finished in the same place drew
+func (stats *overallstats) rate(places []int) {
+	if stats.ratings == nil {
+		return
+	}
+	for a := range places {
+		for b := a + 1; b < len(places); b++ {
+			score := 0.5
+			if places[a] < places[b] {
+				score = 1
+			} else if places[a] > places[b] {
+				score = 0
+			}
+			stats.ratings.Record(stats.keys[a], stats.keys[b], score)
 		}
-		if bot.Name() == os.Args[2] {
-			bot2 = b
+	}
+}
+
+// printPlaces prints how often each bot won and finished in every place, and how often each team won
+func (stats *overallstats) printPlaces(out io.Writer) {
+	games := 0
+	for _, w := range stats.seatWins {
+		games += w
+	}
+	if games == 0 {
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, "Bot\tWins\tWin rate")
+	for place := range stats.places {
+		fmt.Fprintf(w, "\t%s", ordinal(place+1))
+	}
+	fmt.Fprintln(w, "\tMean place")
+	for bot, spec := range stats.specs {
+		fmt.Fprintf(w, "%s\t%d\t%.1f%%", spec, stats.wins[bot], 100*float64(stats.wins[bot])/float64(games))
+		sum := 0
+		for place, count := range stats.places[bot] {
+			fmt.Fprintf(w, "\t%d", count)
+			sum += (place + 1) * count
 		}
+		fmt.Fprintf(w, "\t%.2f\n", float64(sum)/float64(games))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Team\tWins\tWin rate")
+	for team, wins := range stats.seatWins {
+		fmt.Fprintf(w, "%d\t%d\t%.1f%%\n", team+1, wins, 100*float64(wins)/float64(games))
+	}
+	w.Flush()
+}
+
+// ordinal returns the place as 1st, 2nd, 3rd...
+func ordinal(place int) string {
+	switch place {
+	case 1:
+		return "1st"
+	case 2:
+		return "2nd"
+	case 3:
+		return "3rd"
+	}
+	return fmt.Sprintf("%dth", place)
+}
+
+// describe shows the wins on the progress bar, after completed more games
+func (stats *overallstats) describe(completed int) {
+	if stats.pb == nil {
+		return
+	}
+	wins := make([]string, len(stats.wins))
+	for i, w := range stats.wins {
+		wins[i] = fmt.Sprintf("%03d", w)
 	}
+	stats.pb.Describe(strings.Join(wins, " / "))
+	stats.pb.Add(completed)
+}
 
-	if bot1 == nil {
-		fmt.Printf("%s is not a known bot\n", os.Args[1])
-		os.Exit(1)
+func (stats *overallstats) saveCheckpoint() {
+	if err := stats.checkpoint.save(stats.checkpointPath); err != nil {
+		logrus.Errorf("Failed to save checkpoint: %s", err)
 	}
-	if bot2 == nil {
-		fmt.Printf("%s is not a known bot\n", os.Args[2])
-		os.Exit(1)
+}
+
+// restore the games completed before the checkpoint, as if they had been played in this run. Losses
+// played before the checkpoint are printed without the bots' explanations, which are not saved
+func (stats *overallstats) restore(c *checkpoint) error {
+	for _, game := range c.completedGames {
+		r := game.Record
+		if r.Victor < 1 {
+			return fmt.Errorf("game %d has no victor", r.Number)
+		}
+		if len(r.Places) != len(r.Bots) {
+			return fmt.Errorf("game %d has places for %d teams, expected %d", r.Number, len(r.Places), len(r.Bots))
+		}
+		stats.rate(stats.tally(r.Number, r.Victor, r.Places))
+		stats.sumRounds += r.Turns / len(stats.wins)
+		if stats.results != nil {
+			if err := stats.results.Write(r); err != nil {
+				return err
+			}
+		}
+		sim, err := recordedSimulation(game)
+		if err != nil {
+			return err
+		}
+		if arena.Winner(sim, len(stats.wins)) != 0 {
+			stats.losses = append(stats.losses, sim)
+		}
+		stats.profile.record(sim, positions(len(stats.wins)))
+		if len(sim.Crashes) > 0 {
+			stats.crashes = append(stats.crashes, sim)
+		}
+		if stats.book != nil {
+			stats.book.AddGame(sim.Start, sim.Board.Moves, r.Victor, stats.bookPlies)
+		}
 	}
+	stats.checkpoint = c
+	stats.describe(len(c.completedGames))
+	return nil
+}
 
+func usage() {
+	fmt.Println("Chose two to four bots to simulate. Games go through every order the bots can be seated in. Deterministic bots will only play each opening once from every seating.")
+	fmt.Println("With -tournament, any number of bots play in pairs and numRounds is the number of games per pairing.")
+	fmt.Println("With -sprt, bot1 is the baseline and bot2 the candidate, games are played until the test is decided or numRounds have been played.")
+	fmt.Println("With -ratings ratings.json, the bots' ratings are kept across runs, and go run ./cmd/ratings prints the leaderboard.")
+	fmt.Printf("USAGE: %s [flags] bot1 bot2 [bot3...] [numRounds]\n", os.Args[0])
+	flag.PrintDefaults()
+	listBots()
+}
+
+func main() {
+	defer cleanup()
 	opts := &options{
-		threadCount: 10,
-		simCount:    1000,
+		simCount: 1000,
+	}
+	flag.IntVar(&opts.threadCount, "threads", 10, "Number of threads to use")
+	flag.Int64Var(&opts.seed, "seed", time.Now().UnixNano(), "Seed for the first game, every other game n uses seed+n")
+	flag.IntVar(&opts.game, "game", -1, "Replay a single game number and print its moves")
+	flag.BoolVar(&opts.explain, "explain", false, "Print why bots chose their turns when replaying a game")
+	flag.IntVar(&opts.losses, "losses", 0, "Print the last turns of this many games the first bot lost, with the bots' explanations")
+	flag.StringVar(&opts.bookPath, "book-out", "", "Build an opening book from the winners' turns and save it to this file")
+	flag.IntVar(&opts.bookPlies, "book-plies", 8, "Number of turns from each game to add to the opening book")
+	flag.StringVar(&opts.tournament, "tournament", "", "Play a tournament between the bots: roundrobin, gauntlet (the first bot against each other one) or swiss")
+	flag.IntVar(&opts.swissRounds, "swiss-rounds", 3, "Number of rounds in a swiss tournament")
+	flag.BoolVar(&opts.sprt, "sprt", false, "Test whether the second bot is stronger than the first with a sequential probability ratio test")
+	flag.Float64Var(&opts.elo0, "elo0", 0, "Elo difference of the SPRT's null hypothesis")
+	flag.Float64Var(&opts.elo1, "elo1", 10, "Elo difference of the SPRT's alternative hypothesis")
+	flag.Float64Var(&opts.alpha, "alpha", 0.05, "Chance of the SPRT accepting a change that is no stronger")
+	flag.Float64Var(&opts.beta, "beta", 0.05, "Chance of the SPRT rejecting a change that is elo1 stronger")
+	flag.DurationVar(&opts.turnTimeout, "turn-timeout", 0, "Bots that take longer than this to choose a turn forfeit the game, 0 for no limit")
+	openingSuite := flag.String("openings", "", "Start games from \"random\" worker placements, or the positions in this file, instead of the default position")
+	openingBuilds := flag.Int("opening-builds", 0, "Number of random blocks to build in random openings")
+	flag.StringVar(&opts.resultsPath, "results", "", "Write a record of every game to this file, as CSV if it ends in .csv and JSON Lines otherwise")
+	flag.StringVar(&opts.ratingsPath, "ratings", "", "Update the bots' ratings in this file after every game, such as ratings.json. Bots are only rated when this is set")
+	flag.BoolVar(&opts.allocs, "allocs", false, "Count the memory bots allocate choosing their turns, playing one game at a time")
+	cpuProfile := flag.String("cpuprofile", "", "Write a CPU profile to this file")
+	memProfile := flag.String("memprofile", "", "Write a profile of the memory allocated to this file")
+	flag.IntVar(&opts.profileBot, "profile-bot", 0, "Choose this bot's turns (1 for the first bot) through profiledSelectTurn, so go tool pprof -focus=profiledSelectTurn narrows profiles to it")
+	flag.StringVar(&opts.checkpointPath, "checkpoint", "", "Save the completed games to this file, so an interrupted run can be resumed")
+	flag.IntVar(&opts.checkpointEvery, "checkpoint-every", 100, "Number of games between checkpoints")
+	resume := flag.Bool("resume", false, "Resume the run saved in the -checkpoint file, the bots and number of games can be left out. The checkpoint is removed once the run is finished")
+	flag.Usage = usage
+	flag.Parse()
+	args := flag.Args()
+
+	var resumed *checkpoint
+	if *resume {
+		if opts.checkpointPath == "" || opts.tournament != "" || opts.sprt {
+			fmt.Println("-resume needs a -checkpoint, and tournaments and SPRTs cannot be resumed")
+			exit(1)
+		}
+		var err error
+		if resumed, err = loadCheckpoint(opts.checkpointPath); err != nil {
+			fmt.Println(err)
+			exit(1)
+		}
+		if len(args) == 0 {
+			args = append(append(args, resumed.Bots...), strconv.Itoa(resumed.Games))
+		}
+		// The games still to play must be the ones the interrupted run would have played
+		opts.seed = resumed.Seed
+		*openingSuite, *openingBuilds = resumed.Openings, resumed.OpeningBuilds
+	}
+
+	// The number of rounds is the last argument, if it is one
+	rounds := -1
+	if len(args) > 2 {
+		if i, err := strconv.ParseInt(args[len(args)-1], 10, 64); err == nil {
+			rounds = int(i)
+			args = args[:len(args)-1]
+		}
+	}
+	if len(args) < 2 || (len(args) > 4 && opts.tournament == "") || (len(args) != 2 && opts.sprt) {
+		usage()
+		exit(1)
+	}
+	if resumed != nil && (strings.Join(args, " ") != strings.Join(resumed.Bots, " ") || (rounds >= 0 && rounds != resumed.Games)) {
+		fmt.Printf("%s is a checkpoint of %d games between %s\n", opts.checkpointPath, resumed.Games, strings.Join(resumed.Bots, " and "))
+		exit(1)
 	}
 
 	//logrus.SetLevel(logrus.DebugLevel)
-	// Deterministic bots dont need to be run many times (unless explicitly told to)
-	b1 := bot1(0, &santorini.Board{}, nil)
-	b2 := bot2(0, &santorini.Board{}, nil)
+	initializers := make([]santorini.BotInitializer, len(args))
+	names := make([]string, len(args))
+	deterministic := make([]bool, len(args))
+	keys := make([]string, len(args))
+	for i, spec := range args {
+		bot, err := bots.Build(spec)
+		if err != nil {
+			fmt.Println(err)
+			exit(1)
+		}
+		initializers[i] = bot
+		// Deterministic bots dont need to be run many times (unless explicitly told to)
+		b := bot(0, &santorini.Board{}, nil)
+		names[i] = b.Name()
+		deterministic[i] = b.IsDeterministic()
+		closeBot(b)
+		// Every spec of the same bot shares a rating
+		if keys[i], err = bots.CanonicalSpec(spec); err != nil {
+			fmt.Println(err)
+			exit(1)
+		}
+	}
 
-	if b1.IsDeterministic() && b2.IsDeterministic() {
-		opts.simCount = 2
+	if *openingSuite != "" {
+		var err error
+		// Tournament games are between two bots
+		teams := len(args)
+		if opts.tournament != "" {
+			teams = 2
+		}
+		if opts.openings, err = loadOpenings(*openingSuite, *openingBuilds, teams); err != nil {
+			fmt.Println(err)
+			exit(1)
+		}
+	}
+
+	if opts.allocs && opts.threadCount != 1 {
+		// Every game's allocations would be counted
+		logrus.Info("Counting allocations, so only one game is played at a time")
+		opts.threadCount = 1
 	}
-	if len(os.Args) > 3 {
-		if i, err := strconv.ParseInt(os.Args[3], 10, 64); err == nil {
-			opts.simCount = int(i)
-		} else {
-			fmt.Println("Cannot parse integer", os.Args[3])
-			os.Exit(1)
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			fmt.Println(err)
+			exit(1)
 		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			fmt.Println(err)
+			exit(1)
+		}
+		atExit(pprof.StopCPUProfile)
+	}
+	if *memProfile != "" {
+		atExit(func() { writeMemProfile(*memProfile) })
 	}
+	profile := newProfiler(args)
 
-	logrus.Infof("Running %d simulations between %s and %s", opts.simCount, b1.Name(), b2.Name())
+	var ratings *rating.Ledger
+	if opts.ratingsPath != "" && opts.game < 0 {
+		var err error
+		if ratings, err = rating.Load(opts.ratingsPath); err != nil {
+			fmt.Printf("Failed to load ratings: %s\n", err)
+			exit(1)
+		}
+		atExit(func() { saveRatings(ratings, opts.ratingsPath) })
+	}
+	var resultFile *results.Writer
+	if opts.resultsPath != "" && opts.game < 0 {
+		var err error
+		if resultFile, err = results.NewWriter(opts.resultsPath); err != nil {
+			fmt.Printf("Failed to create results file: %s\n", err)
+			exit(1)
+		}
+		atExit(func() { closeResults(resultFile) })
+	}
+
+	if opts.tournament != "" {
+		t, err := newTournament(opts.tournament, args, initializers, deterministic, rounds, opts.swissRounds)
+		if err != nil {
+			fmt.Println(err)
+			exit(1)
+		}
+		t.ratings, t.keys = ratings, keys
+		t.deterministicGames = opts.openings.games(2)
+		t.results = resultFile
+		t.profile = profile
+		t.run(opts)
+		t.printCrosstable(os.Stdout)
+		profile.print(os.Stdout)
+		printCrashes(t.crashes)
+		return
+	}
+
+	if opts.sprt {
+		if allTrue(deterministic) {
+			fmt.Println("Deterministic bots always play the same games, there is nothing to test")
+			exit(1)
+		}
+		test, err := newSPRT(opts.elo0, opts.elo1, opts.alpha, opts.beta)
+		if err != nil {
+			fmt.Println(err)
+			exit(1)
+		}
+		logrus.Infof("Testing %s against %s with seed %d, H0: %g Elo, H1: %g Elo", names[1], names[0], opts.seed, opts.elo0, opts.elo1)
+		stats := &overallstats{wins: make([]int, 2), ratings: ratings, keys: keys, results: resultFile, specs: args, profile: profile}
+		status := test.run(opts, initializers, rounds, stats)
+		test.report(status, args[0], args[1])
+		profile.print(os.Stdout)
+		printCrashes(stats.crashes)
+		return
+	}
+
+	if games := opts.openings.games(len(initializers)); allTrue(deterministic) && games > 0 {
+		opts.simCount = games
+	}
+	if rounds >= 0 {
+		opts.simCount = rounds
+	}
+	if resumed != nil {
+		opts.simCount = resumed.Games
+	}
+
+	if opts.game >= 0 {
+		replay(opts, initializers)
+		return
+	}
+
+	logrus.Infof("Running %d simulations between %s with seed %d", opts.simCount, strings.Join(names, " and "), opts.seed)
 	stats := &overallstats{
-		loseBoards: make([]*santorini.Board, 0, opts.simCount),
-		pb:         progressbar.Default(int64(opts.simCount), "0 / 0"),
+		wins:      make([]int, len(initializers)),
+		losses:    make([]*santorini.Simulation, 0, opts.simCount),
+		pb:        progressbar.Default(int64(opts.simCount), "0 / 0"),
+		bookPlies: opts.bookPlies,
+		ratings:   ratings,
+		keys:      keys,
+		results:   resultFile,
+		specs:     args,
+		profile:   profile,
+	}
+	if opts.bookPath != "" {
+		stats.book = bots.NewBook()
+	}
+	completed := make(map[int]bool)
+	if opts.checkpointPath != "" {
+		stats.checkpointPath, stats.checkpointEvery = opts.checkpointPath, opts.checkpointEvery
+		stats.checkpoint = newCheckpoint(args, opts.seed, opts.simCount, *openingSuite, *openingBuilds)
+		if resumed != nil {
+			if err := stats.restore(resumed); err != nil {
+				fmt.Printf("Failed to resume from %s: %s\n", opts.checkpointPath, err)
+				exit(1)
+			}
+			completed = resumed.completed()
+			logrus.Infof("Resuming with %d of %d games completed", len(completed), opts.simCount)
+		}
 	}
 
 	wg := new(sync.WaitGroup)
@@ -116,20 +529,16 @@ func main() {
 	logrus.Debugf("Starting %d workers", opts.threadCount)
 	for i := 0; i < opts.threadCount; i++ {
 		wg.Add(1)
-		go runner(wg, sims, completedSims)
+		go arena.Runner(wg, sims, completedSims)
 	}
 	wg2.Add(1)
 	go statistician(wg2, completedSims, stats)
 
 	// run all the sim
 	for i := 0; i < opts.simCount; i++ {
-		var sim *santorini.Simulation
-		if i%2 == 0 {
-			sim = santorini.NewSimulator(i, logrus.StandardLogger(), bot1, bot2)
-		} else {
-			sim = santorini.NewSimulator(i, logrus.StandardLogger(), bot2, bot1)
+		if !completed[i] {
+			sims <- newSimulation(opts, i, initializers)
 		}
-		sims <- sim
 	}
 
 	// Wait for all the sims to finish
@@ -140,29 +549,192 @@ func main() {
 	logrus.Debug("Waiting for stats to finish")
 	close(completedSims)
 	wg2.Wait()
+	if stats.checkpoint != nil {
+		// The run is finished, resuming it again would count its games twice
+		stats.checkpoint.close()
+		if err := os.Remove(opts.checkpointPath); err != nil && !os.IsNotExist(err) {
+			logrus.Errorf("Failed to remove checkpoint: %s", err)
+		}
+	}
 
-	logrus.WithFields(map[string]interface{}{
-		"bot1":             b1.Name(),
-		"bot1_wins":        stats.bot1Wins,
-		"bot2":             b2.Name(),
-		"bot2_wins":        stats.bot2Wins,
+	if stats.book != nil {
+		if err := stats.book.Save(opts.bookPath); err != nil {
+			logrus.Errorf("Failed to save opening book: %s", err)
+		}
+	}
+
+	fields := map[string]interface{}{
 		"avg_round_length": stats.sumRounds / opts.simCount,
 		"num_rounds":       opts.simCount,
-	}).Info("Simulation Complete")
+		"seed":             opts.seed,
+	}
+	for i, name := range names {
+		fields[fmt.Sprintf("bot%d", i+1)] = name
+		fields[fmt.Sprintf("bot%d_wins", i+1)] = stats.wins[i]
+	}
+	logrus.WithFields(fields).Info("Simulation Complete")
+	stats.printPlaces(os.Stdout)
+	profile.print(os.Stdout)
+	if opts.allocs && len(completed) > 0 {
+		fmt.Printf("Allocations were only counted in the %d games played since resuming, checkpoints keep turn times but not allocations\n", opts.simCount-len(completed))
+	}
+
+	sort.Slice(stats.losses, func(i, j int) bool {
+		return stats.losses[i].Number < stats.losses[j].Number
+	})
+	for i := 0; i < opts.losses && i < len(stats.losses); i++ {
+		printLoss(stats.losses[i])
+	}
+	printCrashes(stats.crashes)
 }
 
-func runner(wg *sync.WaitGroup, sims chan *santorini.Simulation, results chan *santorini.Simulation) {
-	defer wg.Done()
-	defer logrus.Debug("Runner finished")
-	for sim := range sims {
-		sim.Run()
-		results <- sim
+func saveRatings(ratings *rating.Ledger, path string) {
+	if err := ratings.Save(path); err != nil {
+		logrus.Errorf("Failed to save ratings: %s", err)
+	}
+}
+
+func closeResults(w *results.Writer) {
+	if err := w.Close(); err != nil {
+		logrus.Errorf("Failed to save results: %s", err)
 	}
 }
 
+// newSimulation creates game number i, rotating the order the bots are seated in. Each opening is
+// played once from every seating before moving on to the next
+func newSimulation(opts *options, i int, initializers []santorini.BotInitializer) *santorini.Simulation {
+	seed := opts.seed + int64(i)
+	teams := arena.Seated(i, initializers...)
+	start := santorini.DefaultPosition(len(teams))
+	if opts.openings != nil {
+		start = opts.openings.position(i/arena.Orderings(len(teams)), opts.seed, len(teams))
+	}
+	sim := santorini.NewPositionSimulator(i, seed, start, logrus.StandardLogger(), teams...)
+	sim.Explain = opts.explain || opts.losses > 0
+	sim.TurnTimeout = opts.turnTimeout
+	sim.MeasureAllocs = opts.allocs
+	sim.ProfileTeam = profileTeam(opts, i, positions(len(teams)))
+	return sim
+}
+
+// positions returns the positions on the command line of bots playing every game
+func positions(bots int) []int {
+	p := make([]int, bots)
+	for i := range p {
+		p[i] = i
+	}
+	return p
+}
+
+// profileTeam returns the team the profiled bot plays in game n, bots are the positions on the
+// command line of the bots given to newSimulation
+func profileTeam(opts *options, n int, bots []int) int {
+	for team := range bots {
+		if bots[arena.Seat(team, n, len(bots))] == opts.profileBot-1 {
+			return team + 1
+		}
+	}
+	return 0
+}
+
+func writeMemProfile(path string) {
+	f, err := os.Create(path)
+	if err != nil {
+		logrus.Errorf("Failed to write memory profile: %s", err)
+		return
+	}
+	defer f.Close()
+	if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+		logrus.Errorf("Failed to write memory profile: %s", err)
+	}
+}
+
+// replay a single game and print every turn
+func replay(opts *options, initializers []santorini.BotInitializer) {
+	sim := newSimulation(opts, opts.game, initializers)
+	sim.Run()
+	for i := range sim.Board.Moves {
+		printTurn(sim, i)
+	}
+	fmt.Printf("%s\n\nGame %d (seed %d): Team %d (%s) wins\n", sim.Board, sim.Number, sim.Seed, sim.Board.Victor, sim.Teams[sim.Board.Victor-1].Name())
+	printCrashes([]*santorini.Simulation{sim})
+}
+
+// printCrashes lists the games bots forfeited, with the positions to reproduce them from
+func printCrashes(sims []*santorini.Simulation) {
+	if len(sims) == 0 {
+		return
+	}
+	sort.Slice(sims, func(i, j int) bool {
+		return sims[i].Number < sims[j].Number
+	})
+	fmt.Printf("\n%d games were forfeited\n", len(sims))
+	for _, sim := range sims {
+		for _, crash := range sim.Crashes {
+			fmt.Printf("\nGame %d (seed %d): Team %d (%s) forfeited: %s\n", sim.Number, sim.Seed, crash.Team, crash.Bot, crash.Err)
+			fmt.Printf("     position %q\n", crash.Position)
+			for _, ln := range strings.Split(strings.TrimSpace(crash.Stack), "\n") {
+				if ln != "" {
+					fmt.Printf("     %s\n", ln)
+				}
+			}
+		}
+	}
+}
+
+// printLoss prints the final turns of a lost game, where the mistake most likely is
+func printLoss(sim *santorini.Simulation) {
+	fmt.Printf("\nGame %d (seed %d): Team %d (%s) wins\n", sim.Number, sim.Seed, sim.Board.Victor, sim.Teams[sim.Board.Victor-1].Name())
+	start := len(sim.Board.Moves) - 4
+	if start < 0 {
+		start = 0
+	}
+	for i := start; i < len(sim.Board.Moves); i++ {
+		printTurn(sim, i)
+	}
+	fmt.Println(sim.Board)
+}
+
+// printTurn prints turn i of the game, with its explanation when there is one
+func printTurn(sim *santorini.Simulation, i int) {
+	turn := sim.Board.Moves[i]
+	fmt.Printf("%3d. %s %s\n", i+1, sim.Teams[turn.Team-1].Name(), turn.Notation())
+	if i < len(sim.Explanations) && sim.Explanations[i] != nil {
+		for _, ln := range strings.Split(sim.Explanations[i].String(), "\n") {
+			fmt.Printf("     %s\n", ln)
+		}
+	}
+}
+
+func allTrue(values []bool) bool {
+	for _, v := range values {
+		if !v {
+			return false
+		}
+	}
+	return true
+}
+
 func statistician(wg *sync.WaitGroup, results chan *santorini.Simulation, stats *overallstats) {
 	defer wg.Done()
-	for sim := range results {
-		stats.update(sim)
+	// Save the completed games when interrupted, so the run can be resumed
+	var interrupt chan os.Signal
+	if stats.checkpoint != nil {
+		interrupt = make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		defer signal.Stop(interrupt)
+	}
+	for {
+		select {
+		case sim, ok := <-results:
+			if !ok {
+				return
+			}
+			stats.update(sim)
+		case <-interrupt:
+			stats.saveCheckpoint()
+			fmt.Printf("\nInterrupted after %d games, continue with -resume -checkpoint %s\n", len(stats.checkpoint.completedGames), stats.checkpointPath)
+			exit(130)
+		}
 	}
 }
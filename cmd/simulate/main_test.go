@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	santorini "santorini/pkg"
+	"santorini/pkg/rating"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlaces(t *testing.T) {
+	stats := &overallstats{wins: make([]int, 3), specs: []string{"A", "B", "C"}, keys: []string{"A", "B", "C"}, ratings: rating.NewLedger()}
+	// Game 3 is seated B, C, A. Team 2 (C) won, and team 1 (B) was knocked out
+	sim := &santorini.Simulation{Number: 3, Teams: make([]santorini.TurnSelector, 3), Board: &santorini.Board{Victor: 2}, Eliminated: []int{1}}
+	assert.Equal(t, []int{2, 3, 1}, stats.tally(sim.Number, sim.Board.Victor, sim.Places()))
+	assert.Equal(t, []int{0, 0, 1}, stats.wins)
+	assert.Equal(t, []int{0, 1, 0}, stats.seatWins)
+	assert.Equal(t, []int{0, 0, 1}, stats.places[1])
+
+	stats.rate([]int{2, 3, 1})
+	assert.Equal(t, 2, stats.ratings.Get("C").Wins)
+	assert.Equal(t, 1, stats.ratings.Get("A").Wins)
+	assert.Equal(t, 2, stats.ratings.Get("B").Losses)
+
+	out := new(bytes.Buffer)
+	stats.printPlaces(out)
+	assert.Contains(t, out.String(), "C    1     100.0%    1    0    0    1.00")
+	assert.Contains(t, out.String(), "2     1     100.0%")
+}
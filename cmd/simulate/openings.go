@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"os"
+	santorini "santorini/pkg"
+	"santorini/pkg/arena"
+	"strings"
+)
+
+// openings are the positions games start from. Consecutive games share an opening until the bots
+// have played it in every seating, so deterministic bots play a different game for each opening
+type openings struct {
+	random    bool               // Place the workers randomly
+	builds    int                // Random blocks built before placing the workers
+	positions []*santorini.Board // Curated positions, used in turn
+}
+
+// loadOpenings reads the suite: "random" for random openings, or a file of positions one per line
+// where blank lines and lines starting with # are skipped. Every position must have team 1 to move,
+// with at least one turn to take
+func loadOpenings(suite string, builds, teams int) (*openings, error) {
+	if suite == "random" {
+		return &openings{random: true, builds: builds}, nil
+	}
+	f, err := os.Open(suite)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	o := &openings{}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		board, team, err := santorini.ParseNotation(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", suite, n, err)
+		}
+		if team != 1 {
+			return nil, fmt.Errorf("%s:%d: openings must have team 1 to move", suite, n)
+		}
+		if len(board.Clone().GetValidTurns(1)) == 0 {
+			return nil, fmt.Errorf("%s:%d: team 1 has no turns in the opening", suite, n)
+		}
+		if len(board.Teams) != teams {
+			return nil, fmt.Errorf("%s:%d: opening has %d teams for %d bots", suite, n, len(board.Teams), teams)
+		}
+		o.positions = append(o.positions, board)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(o.positions) == 0 {
+		return nil, fmt.Errorf("%s has no openings", suite)
+	}
+	return o, nil
+}
+
+// position returns opening k, random openings are made from the seed so games can be replayed
+func (o *openings) position(k int, seed int64, teams int) *santorini.Board {
+	if o.random {
+		return santorini.RandomPosition(rand.New(rand.NewSource(seed+int64(k))), teams, o.builds)
+	}
+	return o.positions[k%len(o.positions)]
+}
+
+// games returns how many games deterministic bots need to play every opening in every seating,
+// or -1 when every opening is different
+func (o *openings) games(bots int) int {
+	switch {
+	case o == nil:
+		return arena.Orderings(bots)
+	case o.random:
+		return -1
+	}
+	return len(o.positions) * arena.Orderings(bots)
+}
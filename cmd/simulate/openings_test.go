@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"santorini/bots"
+	santorini "santorini/pkg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "openings.txt")
+	suite := "# Corners\n00000/00000/00000/00000/00000 1.1:a1,1.2:e5,2.1:a5,2.2:e1 1\n\n01000/00100/00000/00000/00000 1.1:b2,1.2:c3,2.1:d4,2.2:e5 1\n"
+	assert.NoError(t, os.WriteFile(path, []byte(suite), 0644))
+	o, err := loadOpenings(path, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, o.games(2))
+
+	// Both bots play each opening from both seats
+	opts := &options{seed: 1, openings: o}
+	initializers := []santorini.BotInitializer{bots.NewKyleBot, bots.NewBasicBot}
+	var starts, first []string
+	for i := 0; i < 4; i++ {
+		sim := newSimulation(opts, i, initializers)
+		starts = append(starts, sim.Start.Notation(1))
+		first = append(first, sim.Teams[0].Name())
+	}
+	assert.Equal(t, starts[0], starts[1])
+	assert.Equal(t, starts[2], starts[3])
+	assert.NotEqual(t, starts[0], starts[2])
+	assert.Equal(t, []string{"KyleBot", "BasicBot", "KyleBot", "BasicBot"}, first)
+
+	_, err = loadOpenings(path, 0, 3)
+	assert.Error(t, err)
+	assert.NoError(t, os.WriteFile(path, []byte("00000/00000/00000/00000/00000 1.1:a1,1.2:e5,2.1:a5,2.2:e1 2\n"), 0644))
+	_, err = loadOpenings(path, 0, 2)
+	assert.Error(t, err)
+	// Team 1's workers are walled in by domes
+	assert.NoError(t, os.WriteFile(path, []byte("00400/44400/00000/00000/00000 1.1:a1,1.2:b1,2.1:e5,2.2:d5 1\n"), 0644))
+	_, err = loadOpenings(path, 0, 2)
+	assert.Error(t, err)
+
+	random, err := loadOpenings("random", 4, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, -1, random.games(2))
+	assert.Equal(t, random.position(3, 1, 2).Notation(1), random.position(3, 1, 2).Notation(1))
+	assert.Equal(t, 2, (*openings)(nil).games(2))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	santorini "santorini/pkg"
+	"santorini/pkg/arena"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// profiler collects how long each bot takes to choose its turns, and how much it allocates doing so
+type profiler struct {
+	specs   []string
+	times   [][]float64 // Milliseconds of every turn, by bot
+	bytes   [][]float64
+	objects [][]float64
+}
+
+func newProfiler(specs []string) *profiler {
+	return &profiler{
+		specs:   specs,
+		times:   make([][]float64, len(specs)),
+		bytes:   make([][]float64, len(specs)),
+		objects: make([][]float64, len(specs)),
+	}
+}
+
+// record the turns of a game, bots are the bots given to newSimulation as positions in specs
+func (p *profiler) record(sim *santorini.Simulation, bots []int) {
+	if p == nil {
+		return
+	}
+	for i, turn := range sim.Board.Moves {
+		bot := bots[arena.Seat(turn.Team-1, sim.Number, len(bots))]
+		if i < len(sim.TurnTimes) {
+			p.times[bot] = append(p.times[bot], float64(sim.TurnTimes[i])/float64(time.Millisecond))
+		}
+		if i < len(sim.TurnAllocs) {
+			p.bytes[bot] = append(p.bytes[bot], float64(sim.TurnAllocs[i].Bytes))
+			p.objects[bot] = append(p.objects[bot], float64(sim.TurnAllocs[i].Objects))
+		}
+	}
+}
+
+// print the mean, median, 95th percentile and maximum of each bot's turns
+func (p *profiler) print(out io.Writer) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Bot\tPer turn\tTurns\tMean\tP50\tP95\tMax")
+	for bot, spec := range p.specs {
+		printDistribution(w, spec, "ms", p.times[bot], "%.3f")
+		printDistribution(w, spec, "bytes", p.bytes[bot], "%.0f")
+		printDistribution(w, spec, "allocs", p.objects[bot], "%.0f")
+	}
+	w.Flush()
+}
+
+func printDistribution(w io.Writer, spec, unit string, values []float64, format string) {
+	if len(values) == 0 {
+		return
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	fmt.Fprintf(w, "%s\t%s\t%d", spec, unit, len(sorted))
+	for _, v := range []float64{sum / float64(len(sorted)), percentile(sorted, 50), percentile(sorted, 95), sorted[len(sorted)-1]} {
+		fmt.Fprintf(w, "\t"+format, v)
+	}
+	fmt.Fprintln(w)
+}
+
+// percentile returns the value p percent of the sorted values are at or below, by the nearest rank
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	santorini "santorini/pkg"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfiler(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	assert.Equal(t, 5.0, percentile(sorted, 50))
+	assert.Equal(t, 10.0, percentile(sorted, 95))
+	assert.Equal(t, 1.0, percentile(sorted, 0))
+
+	// In odd games the second bot goes first
+	p := newProfiler([]string{"A", "B"})
+	p.record(&santorini.Simulation{
+		Number:     1,
+		Board:      &santorini.Board{Moves: []santorini.Turn{{Team: 1}, {Team: 2}, {Team: 1}}},
+		TurnTimes:  []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond},
+		TurnAllocs: []santorini.Allocs{{Bytes: 10, Objects: 1}, {Bytes: 20, Objects: 2}, {Bytes: 30, Objects: 3}},
+	}, []int{0, 1})
+	assert.Equal(t, []float64{2}, p.times[0])
+	assert.Equal(t, []float64{1, 3}, p.times[1])
+	assert.Equal(t, []float64{10, 30}, p.bytes[1])
+
+	out := new(bytes.Buffer)
+	p.print(out)
+	assert.Contains(t, out.String(), "B    ms        2      2.000  1.000  3.000  3.000")
+
+	opts := &options{profileBot: 3}
+	assert.Equal(t, 2, profileTeam(opts, 0, []int{0, 2}))
+	assert.Equal(t, 1, profileTeam(opts, 1, []int{0, 2}))
+	assert.Equal(t, 0, profileTeam(opts, 0, []int{0, 1}))
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	santorini "santorini/pkg"
+	"santorini/pkg/arena"
+	"santorini/pkg/results"
+
+	"github.com/sirupsen/logrus"
+)
+
+// newRecord describes the game, specs are the bots in the order they were given to newSimulation
+func newRecord(sim *santorini.Simulation, specs []string) results.Record {
+	bots := make([]string, len(specs))
+	for team := range bots {
+		bots[team] = specs[arena.Seat(team, sim.Number, len(specs))]
+	}
+	return results.NewRecord(sim, bots)
+}
+
+// recordResult writes the game, logging rather than stopping the simulation when the file cannot be written
+func recordResult(w *results.Writer, sim *santorini.Simulation, specs []string) {
+	if w == nil {
+		return
+	}
+	if err := w.Write(newRecord(sim, specs)); err != nil {
+		logrus.Errorf("Failed to write the results of game %d: %s", sim.Number, err)
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"santorini/bots"
+	santorini "santorini/pkg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRecord(t *testing.T) {
+	opts := &options{seed: 1}
+	sim := newSimulation(opts, 1, []santorini.BotInitializer{bots.NewKyleBot, bots.NewBasicBot})
+	sim.Run()
+
+	// The bots swap seats every game
+	r := newRecord(sim, []string{"KyleBot", "BasicBot"})
+	assert.Equal(t, []string{"BasicBot", "KyleBot"}, r.Bots)
+	assert.Equal(t, "BasicBot", r.First)
+	assert.Equal(t, r.Bots[sim.Board.Victor-1], r.Winner)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	santorini "santorini/pkg"
+	"santorini/pkg/arena"
+	"sync"
+
+	"github.com/schollz/progressbar/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// sprt is a sequential probability ratio test of whether a candidate bot is elo0 (H0) or elo1 (H1)
+// Elo stronger than a baseline bot. Games are played until the log likelihood ratio of H1 over H0
+// crosses one of the bounds, which are set by the accepted rates of false positives (alpha) and
+// false negatives (beta)
+type sprt struct {
+	elo0, elo1  float64
+	alpha, beta float64
+	// Results of the candidate against the baseline
+	wins, losses, draws int
+}
+
+// Outcomes of the test
+const (
+	sprtContinue = iota
+	sprtAcceptH0
+	sprtAcceptH1
+)
+
+func newSPRT(elo0, elo1, alpha, beta float64) (*sprt, error) {
+	if elo1 <= elo0 {
+		return nil, fmt.Errorf("elo1 (%g) must be greater than elo0 (%g)", elo1, elo0)
+	}
+	if alpha <= 0 || alpha >= 1 || beta <= 0 || beta >= 1 {
+		return nil, fmt.Errorf("alpha and beta must be between 0 and 1")
+	}
+	return &sprt{elo0: elo0, elo1: elo1, alpha: alpha, beta: beta}, nil
+}
+
+func (s *sprt) games() int {
+	return s.wins + s.losses + s.draws
+}
+
+// add a game, the score is the candidate's: 1 for a win, 0.5 for a draw and 0 for a loss
+func (s *sprt) add(score float64) {
+	switch score {
+	case 1:
+		s.wins++
+	case 0:
+		s.losses++
+	default:
+		s.draws++
+	}
+}
+
+// bounds returns the log likelihood ratios that accept H0 and H1
+func (s *sprt) bounds() (float64, float64) {
+	return math.Log(s.beta / (1 - s.alpha)), math.Log((1 - s.beta) / s.alpha)
+}
+
+// score returns the candidate's mean score and the variance of a single game's score. Half a win
+// and half a loss are added to the results, so a bot that has won every game still has a variance
+func (s *sprt) score() (float64, float64) {
+	wins, losses, draws := float64(s.wins)+0.5, float64(s.losses)+0.5, float64(s.draws)
+	n := wins + losses + draws
+	mean := (wins + draws/2) / n
+	variance := (wins*(1-mean)*(1-mean) + draws*(0.5-mean)*(0.5-mean) + losses*mean*mean) / n
+	return mean, variance
+}
+
+// llr returns the log likelihood ratio of H1 over H0, using the normal approximation of the scores
+func (s *sprt) llr() float64 {
+	mean, variance := s.score()
+	s0, s1 := expectedScore(s.elo0), expectedScore(s.elo1)
+	return float64(s.games()) * (s1 - s0) * (2*mean - s0 - s1) / (2 * variance)
+}
+
+// status returns whether the test has accepted a hypothesis yet
+func (s *sprt) status() int {
+	lower, upper := s.bounds()
+	switch llr := s.llr(); {
+	case llr <= lower:
+		return sprtAcceptH0
+	case llr >= upper:
+		return sprtAcceptH1
+	}
+	return sprtContinue
+}
+
+// elo returns the estimated Elo difference of the candidate over the baseline, with its 95% error
+func (s *sprt) elo() (float64, float64) {
+	mean, variance := s.score()
+	diff := eloDifference(mean)
+	margin := 1.96 * math.Sqrt(variance/float64(s.games()))
+	return diff, (eloDifference(mean+margin) - eloDifference(mean-margin)) / 2
+}
+
+// expectedScore returns the score expected of a bot that is elo stronger than its opponent
+func expectedScore(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
+
+// eloDifference is the inverse of expectedScore, clamped so a perfect score is not infinite
+func eloDifference(score float64) float64 {
+	score = math.Max(0.001, math.Min(0.999, score))
+	return -400 * math.Log10(1/score-1)
+}
+
+// run games between the baseline (bot 0) and the candidate (bot 1) until a hypothesis is accepted,
+// or maxGames have been played when it is not negative
+func (s *sprt) run(opts *options, initializers []santorini.BotInitializer, maxGames int, stats *overallstats) int {
+	wg := new(sync.WaitGroup)
+	sims := make(chan *santorini.Simulation)
+	completedSims := make(chan *santorini.Simulation)
+	for i := 0; i < opts.threadCount; i++ {
+		wg.Add(1)
+		go arena.Runner(wg, sims, completedSims)
+	}
+	pb := progressbar.Default(int64(maxGames), "LLR 0.00")
+	lower, upper := s.bounds()
+
+	// Keep every runner busy until the test is decided, then let the running games finish
+	queued, status := 0, sprtContinue
+	queue := func() {
+		for ; queued-s.games() < opts.threadCount && (maxGames < 0 || queued < maxGames); queued++ {
+			sims <- newSimulation(opts, queued, initializers)
+		}
+	}
+	queue()
+	for s.games() < queued {
+		sim := <-completedSims
+		stats.update(sim)
+		switch {
+		case sim.Board.Victor == 0:
+			s.add(0.5)
+		case arena.Winner(sim, 2) == 1:
+			s.add(1)
+		default:
+			s.add(0)
+		}
+		pb.Describe(fmt.Sprintf("LLR %.2f [%.2f, %.2f]", s.llr(), lower, upper))
+		pb.Add(1)
+		if status == sprtContinue {
+			if status = s.status(); status == sprtContinue {
+				queue()
+			}
+		}
+	}
+	close(sims)
+	wg.Wait()
+	pb.Finish()
+	fmt.Println()
+	return status
+}
+
+// report logs the outcome of the test
+func (s *sprt) report(status int, baseline, candidate string) {
+	lower, upper := s.bounds()
+	elo, margin := s.elo()
+	result := "inconclusive"
+	switch status {
+	case sprtAcceptH0:
+		result = fmt.Sprintf("H0 accepted, %s is not %g Elo stronger than %s", candidate, s.elo1, baseline)
+	case sprtAcceptH1:
+		result = fmt.Sprintf("H1 accepted, %s is more than %g Elo stronger than %s", candidate, s.elo0, baseline)
+	}
+	logrus.WithFields(logrus.Fields{
+		"games":  s.games(),
+		"wins":   s.wins,
+		"losses": s.losses,
+		"draws":  s.draws,
+		"llr":    fmt.Sprintf("%.2f", s.llr()),
+		"bounds": fmt.Sprintf("[%.2f, %.2f]", lower, upper),
+		"elo":    fmt.Sprintf("%.1f +/- %.1f", elo, margin),
+	}).Info("SPRT Complete")
+	fmt.Println(result)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSPRT(t *testing.T) {
+	_, err := newSPRT(5, 0, 0.05, 0.05)
+	assert.Error(t, err)
+
+	test, err := newSPRT(0, 10, 0.05, 0.05)
+	assert.NoError(t, err)
+	assert.Equal(t, sprtContinue, test.status())
+	for i := 0; i < 20; i++ {
+		test.add(1)
+	}
+	assert.Equal(t, sprtAcceptH1, test.status())
+
+	// Evenly matched bots are not 10 Elo apart
+	test, _ = newSPRT(0, 10, 0.05, 0.05)
+	test.wins, test.losses = 5000, 5000
+	assert.Equal(t, sprtAcceptH0, test.status())
+	elo, margin := test.elo()
+	assert.InDelta(t, 0, elo, 0.1)
+	assert.InDelta(t, 6.8, margin, 0.1)
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	santorini "santorini/pkg"
+	"santorini/pkg/arena"
+	"santorini/pkg/rating"
+	"santorini/pkg/results"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/schollz/progressbar/v3"
+)
+
+// defaultTournamentGames is the number of games each pairing plays unless told otherwise
+const defaultTournamentGames = 100
+
+// record is one bot's results against another
+type record struct {
+	wins   int
+	losses int
+	draws  int
+}
+
+func (r record) games() int {
+	return r.wins + r.losses + r.draws
+}
+
+// pairing is a match between two bots, by their position on the command line
+type pairing struct {
+	a, b int
+}
+
+type tournament struct {
+	format        string // roundrobin, gauntlet or swiss
+	specs         []string
+	bots          []santorini.BotInitializer
+	deterministic []bool
+	games         int        // Games per pairing, or -1 to choose by whether the bots are deterministic
+	rounds        int        // Rounds of a swiss tournament
+	table         [][]record // table[a][b] is a's record against b
+	byes          []int      // Swiss rounds each bot sat out
+	ratings       *rating.Ledger
+	keys          []string // Canonical specs the bots are rated by
+	results       *results.Writer
+	crashes       []*santorini.Simulation // Games a bot forfeited
+	profile       *profiler
+	// Games a pairing of deterministic bots plays to see every opening, -1 when they are all different
+	deterministicGames int
+}
+
+func newTournament(format string, specs []string, bots []santorini.BotInitializer, deterministic []bool, games, rounds int) (*tournament, error) {
+	switch format {
+	case "roundrobin", "gauntlet", "swiss":
+	default:
+		return nil, fmt.Errorf("unknown tournament %q, use roundrobin, gauntlet or swiss", format)
+	}
+	if format == "swiss" && rounds < 1 {
+		return nil, fmt.Errorf("a swiss tournament needs at least 1 round")
+	}
+	t := &tournament{
+		format:        format,
+		specs:         specs,
+		bots:          bots,
+		deterministic: deterministic,
+		games:         games,
+		rounds:        rounds,
+		table:         make([][]record, len(bots)),
+		byes:          make([]int, len(bots)),
+		// One game with each bot going first
+		deterministicGames: 2,
+	}
+	for i := range t.table {
+		t.table[i] = make([]record, len(bots))
+	}
+	return t, nil
+}
+
+// gamesFor returns the number of games the bots of the pairing play against each other
+func (t *tournament) gamesFor(p pairing) int {
+	if t.games >= 0 {
+		return t.games
+	}
+	if t.deterministic[p.a] && t.deterministic[p.b] && t.deterministicGames > 0 {
+		return t.deterministicGames
+	}
+	return defaultTournamentGames
+}
+
+// pairings returns the matches of a round, or nil once the tournament is over
+func (t *tournament) pairings(round int) []pairing {
+	var pairings []pairing
+	switch t.format {
+	case "roundrobin":
+		if round > 0 {
+			return nil
+		}
+		for a := range t.bots {
+			for b := a + 1; b < len(t.bots); b++ {
+				pairings = append(pairings, pairing{a, b})
+			}
+		}
+	case "gauntlet":
+		if round > 0 {
+			return nil
+		}
+		for b := 1; b < len(t.bots); b++ {
+			pairings = append(pairings, pairing{0, b})
+		}
+	case "swiss":
+		if round >= t.rounds {
+			return nil
+		}
+		pairings = t.swissPairings()
+	}
+	return pairings
+}
+
+// swissPairings pairs every bot with the closest ranked bot it has not played yet. With an odd
+// number of bots, the lowest ranked bot that has sat out the fewest rounds gets a bye
+func (t *tournament) swissPairings() []pairing {
+	unpaired := t.standings()
+	if len(unpaired)%2 == 1 {
+		bye := len(unpaired) - 1
+		for i := len(unpaired) - 1; i >= 0; i-- {
+			if t.byes[unpaired[i]] < t.byes[unpaired[bye]] {
+				bye = i
+			}
+		}
+		t.byes[unpaired[bye]]++
+		unpaired = append(unpaired[:bye], unpaired[bye+1:]...)
+	}
+
+	if pairings, ok := t.pairWithoutRematches(unpaired); ok {
+		return pairings
+	}
+	// Every way of pairing the bots has a rematch, so pair them by rank alone
+	var pairings []pairing
+	for i := 0; i+1 < len(unpaired); i += 2 {
+		pairings = append(pairings, pairing{unpaired[i], unpaired[i+1]})
+	}
+	return pairings
+}
+
+// pairWithoutRematches pairs the first bot with the next ranked bot it has not played, as long as
+// the rest of the bots can still be paired without rematches
+func (t *tournament) pairWithoutRematches(unpaired []int) ([]pairing, bool) {
+	if len(unpaired) == 0 {
+		return nil, true
+	}
+	a := unpaired[0]
+	for i := 1; i < len(unpaired); i++ {
+		b := unpaired[i]
+		if t.table[a][b].games() > 0 {
+			continue
+		}
+		rest := make([]int, 0, len(unpaired)-2)
+		rest = append(rest, unpaired[1:i]...)
+		rest = append(rest, unpaired[i+1:]...)
+		if pairings, ok := t.pairWithoutRematches(rest); ok {
+			return append([]pairing{{a, b}}, pairings...), true
+		}
+	}
+	return nil, false
+}
+
+// score returns the bot's points, one for every win and half for every draw, and the games it played
+func (t *tournament) score(bot int) (float64, int) {
+	points, games := 0.0, 0
+	for _, r := range t.table[bot] {
+		points += float64(r.wins) + float64(r.draws)/2
+		games += r.games()
+	}
+	return points, games
+}
+
+// percentage returns the share of the available points the bot scored
+func (t *tournament) percentage(bot int) float64 {
+	points, games := t.score(bot)
+	if games == 0 {
+		return 0
+	}
+	return 100 * points / float64(games)
+}
+
+// standings returns the bots from best to worst scoring, by their share of the points so
+// byes and uneven numbers of games do not count against a bot
+func (t *tournament) standings() []int {
+	bots := make([]int, len(t.bots))
+	for i := range bots {
+		bots[i] = i
+	}
+	sort.SliceStable(bots, func(i, j int) bool {
+		return t.percentage(bots[i]) > t.percentage(bots[j])
+	})
+	return bots
+}
+
+// record the result of a game played by the pairing
+func (t *tournament) record(p pairing, sim *santorini.Simulation) {
+	recordResult(t.results, sim, []string{t.specs[p.a], t.specs[p.b]})
+	t.profile.record(sim, []int{p.a, p.b})
+	if len(sim.Crashes) > 0 {
+		t.crashes = append(t.crashes, sim)
+	}
+	if sim.Board.Victor == 0 {
+		t.table[p.a][p.b].draws++
+		t.table[p.b][p.a].draws++
+		t.rate(p.a, p.b, 0.5)
+		return
+	}
+	winner, loser := p.a, p.b
+	if arena.Winner(sim, 2) == 1 {
+		winner, loser = p.b, p.a
+	}
+	t.table[winner][loser].wins++
+	t.table[loser][winner].losses++
+	t.rate(winner, loser, 1)
+}
+
+// rate records the game in the ratings, if the bots are being rated
+func (t *tournament) rate(a, b int, score float64) {
+	if t.ratings != nil {
+		t.ratings.Record(t.keys[a], t.keys[b], score)
+	}
+}
+
+// run plays every round of the tournament on a pool of runners
+func (t *tournament) run(opts *options) {
+	wg := new(sync.WaitGroup)
+	sims := make(chan *santorini.Simulation)
+	completedSims := make(chan *santorini.Simulation)
+	for i := 0; i < opts.threadCount; i++ {
+		wg.Add(1)
+		go arena.Runner(wg, sims, completedSims)
+	}
+
+	// Swiss pairings depend on the results, so only the other formats know how many games there are
+	total := -1
+	if t.format != "swiss" {
+		total = 0
+		for _, p := range t.pairings(0) {
+			total += t.gamesFor(p)
+		}
+	}
+	pb := progressbar.Default(int64(total), t.format)
+
+	number := 0
+	for round := 0; ; round++ {
+		pairings := t.pairings(round)
+		if pairings == nil {
+			break
+		}
+		// Every game gets its own number, so it has its own seed and the bots alternate going first
+		games := make(map[int]pairing)
+		var batch []*santorini.Simulation
+		for _, p := range pairings {
+			// Start every pairing on an even number, so both bots play each opening from both seats
+			number += number % 2
+			for g := 0; g < t.gamesFor(p); g++ {
+				games[number] = p
+				sim := newSimulation(opts, number, []santorini.BotInitializer{t.bots[p.a], t.bots[p.b]})
+				sim.ProfileTeam = profileTeam(opts, number, []int{p.a, p.b})
+				batch = append(batch, sim)
+				number++
+			}
+		}
+		go func() {
+			for _, sim := range batch {
+				sims <- sim
+			}
+		}()
+		for range batch {
+			sim := <-completedSims
+			t.record(games[sim.Number], sim)
+			pb.Add(1)
+		}
+	}
+	close(sims)
+	wg.Wait()
+	pb.Finish()
+	fmt.Println()
+}
+
+// printCrosstable prints every bot's wins, losses and draws against each other bot, best bot first
+func (t *tournament) printCrosstable(out io.Writer) {
+	standings := t.standings()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, "#\tBot")
+	for i := range standings {
+		fmt.Fprintf(w, "\t%d", i+1)
+	}
+	fmt.Fprintln(w, "\tScore\t%")
+	for i, a := range standings {
+		fmt.Fprintf(w, "%d\t%s", i+1, t.specs[a])
+		for _, b := range standings {
+			switch r := t.table[a][b]; {
+			case a == b:
+				fmt.Fprint(w, "\t-")
+			case r.games() == 0:
+				fmt.Fprint(w, "\t.")
+			default:
+				fmt.Fprintf(w, "\t%d-%d-%d", r.wins, r.losses, r.draws)
+			}
+		}
+		points, _ := t.score(a)
+		fmt.Fprintf(w, "\t%g\t%.1f\n", points, t.percentage(a))
+	}
+	w.Flush()
+	fmt.Fprintln(out, "Each cell is the row bot's wins-losses-draws against the column bot")
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	santorini "santorini/pkg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTournament(t *testing.T, format string, bots int) *tournament {
+	specs := make([]string, bots)
+	for i := range specs {
+		specs[i] = string(rune('A' + i))
+	}
+	tour, err := newTournament(format, specs, make([]santorini.BotInitializer, bots), make([]bool, bots), 2, 3)
+	assert.NoError(t, err)
+	return tour
+}
+
+func TestTournamentPairings(t *testing.T) {
+	assert.Len(t, testTournament(t, "roundrobin", 4).pairings(0), 6)
+	assert.Nil(t, testTournament(t, "roundrobin", 4).pairings(1))
+	assert.Equal(t, []pairing{{0, 1}, {0, 2}}, testTournament(t, "gauntlet", 3).pairings(0))
+
+	_, err := newTournament("knockout", nil, nil, nil, 2, 3)
+	assert.Error(t, err)
+}
+
+func TestSwissAvoidsRematches(t *testing.T) {
+	tour := testTournament(t, "swiss", 5)
+	for round := 0; round < 3; round++ {
+		pairings := tour.pairings(round)
+		assert.Len(t, pairings, 2)
+		for _, p := range pairings {
+			assert.Equal(t, 0, tour.table[p.a][p.b].games(), "round %d rematch %v", round, p)
+			// The first bot always wins when it goes first
+			tour.record(p, &santorini.Simulation{Number: 0, Board: &santorini.Board{Victor: 1}})
+			tour.record(p, &santorini.Simulation{Number: 1, Board: &santorini.Board{Victor: 1}})
+		}
+	}
+	assert.Nil(t, tour.pairings(3))
+	// Three rounds of five bots means three different bots sat out
+	byes := 0
+	for _, b := range tour.byes {
+		assert.LessOrEqual(t, b, 1)
+		byes += b
+	}
+	assert.Equal(t, 3, byes)
+}
+
+func TestCrosstable(t *testing.T) {
+	tour := testTournament(t, "roundrobin", 3)
+	// B wins both games against A, A and C split, B and C draw
+	tour.record(pairing{0, 1}, &santorini.Simulation{Number: 0, Board: &santorini.Board{Victor: 2}})
+	tour.record(pairing{0, 1}, &santorini.Simulation{Number: 1, Board: &santorini.Board{Victor: 1}})
+	tour.record(pairing{0, 2}, &santorini.Simulation{Number: 2, Board: &santorini.Board{Victor: 1}})
+	tour.record(pairing{0, 2}, &santorini.Simulation{Number: 3, Board: &santorini.Board{Victor: 1}})
+	tour.record(pairing{1, 2}, &santorini.Simulation{Number: 4, Board: &santorini.Board{Victor: 0}})
+	assert.Equal(t, record{wins: 2}, tour.table[1][0])
+	assert.Equal(t, record{wins: 1, losses: 1}, tour.table[0][2])
+	assert.Equal(t, record{draws: 1}, tour.table[2][1])
+
+	var out bytes.Buffer
+	tour.printCrosstable(&out)
+	assert.Equal(t, `#  Bot  1      2      3      Score  %
+1  B    -      0-0-1  2-0-0  2.5    83.3
+2  C    0-0-1  -      1-1-0  1.5    50.0
+3  A    0-2-0  1-1-0  -      1      25.0
+Each cell is the row bot's wins-losses-draws against the column bot
+`, out.String())
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"santorini/pkg/solver"
+
+	"github.com/schollz/progressbar/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// Solve random late game positions on a small board and save them to a tablebase
+func main() {
+	size := flag.Int("size", 3, fmt.Sprintf("Board size, from 2 to %d", solver.MaxSize))
+	positions := flag.Int("positions", 1000, "Number of random positions to solve")
+	seed := flag.Int64("seed", 1, "Seed for the random positions")
+	depth := flag.Int("depth", 16, "Maximum number of turns to search")
+	nodes := flag.Int("nodes", 1000000, "Maximum positions searched for each random position")
+	out := flag.String("out", "", "Tablebase file, extended if it already exists (default size<n>.tb)")
+	flag.Parse()
+
+	if *out == "" {
+		*out = fmt.Sprintf("size%d.tb", *size)
+	}
+
+	tb := solver.NewTablebase(*size)
+	if _, err := os.Stat(*out); err == nil {
+		if tb, err = solver.LoadTablebase(*out); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if tb.Size != *size {
+			fmt.Printf("%s is a tablebase for size %d boards\n", *out, tb.Size)
+			os.Exit(1)
+		}
+	}
+	logrus.Infof("Solving %d positions on a %dx%d board, starting with %d proven", *positions, *size, *size, tb.Len())
+
+	counts := make(map[solver.Result]int)
+	rng := rand.New(rand.NewSource(*seed))
+	pb := progressbar.Default(int64(*positions))
+	for i := 0; i < *positions; i++ {
+		s := solver.NewSolver(tb)
+		s.MaxNodes = *nodes
+		entry, err := s.Solve(solver.RandomPosition(rng, *size), 1, *depth)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		counts[entry.Result]++
+		pb.Describe(fmt.Sprintf("%d proven", tb.Len()))
+		pb.Add(1)
+	}
+
+	if err := tb.Save(*out); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	logrus.WithFields(map[string]interface{}{
+		"wins":    counts[solver.Win],
+		"losses":  counts[solver.Loss],
+		"unknown": counts[solver.Unknown],
+		"proven":  tb.Len(),
+		"out":     *out,
+	}).Info("Solving Complete")
+}
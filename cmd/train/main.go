@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"santorini/bots"
+	santorini "santorini/pkg"
+	"santorini/pkg/arena"
+	"santorini/pkg/nn"
+	"sync"
+
+	"github.com/schollz/progressbar/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// Train a neural network on games it plays against itself. Every generation plays a batch
+// of self-play games with some random exploration, trains on the finished games and saves
+// the network so a run can be stopped and continued at any point.
+type options struct {
+	threadCount int
+	generations int
+	games       int // Self-play games per generation
+	epochs      int // Passes over each generation's games
+	evalGames   int // Games against RandomBot after each generation
+	hidden      int
+	rate        float64
+	epsilon     float64
+	seed        int64
+	modelPath   string
+	outPath     string
+}
+
+func main() {
+	opts := &options{}
+	flag.IntVar(&opts.threadCount, "threads", 10, "Number of threads to use")
+	flag.IntVar(&opts.generations, "generations", 10, "Number of self-play generations")
+	flag.IntVar(&opts.games, "games", 200, "Self-play games per generation")
+	flag.IntVar(&opts.epochs, "epochs", 2, "Training passes over each generation's games")
+	flag.IntVar(&opts.evalGames, "eval", 20, "Games against RandomBot after each generation, 0 to skip")
+	flag.IntVar(&opts.hidden, "hidden", 64, "Hidden units of a new network")
+	flag.Float64Var(&opts.rate, "rate", 0.001, "Learning rate")
+	flag.Float64Var(&opts.epsilon, "epsilon", 0.1, "Chance of a random turn during self-play")
+	flag.Int64Var(&opts.seed, "seed", 1, "Seed for the network, the games and training")
+	flag.StringVar(&opts.modelPath, "model", "", "Network to continue training (defaults to a new network)")
+	flag.StringVar(&opts.outPath, "out", "network.json", "File to save the network to after every generation")
+	flag.Parse()
+
+	rng := rand.New(rand.NewSource(opts.seed))
+	net := nn.New(rng, santorini.NewBoard().Size, opts.hidden)
+	if opts.modelPath != "" {
+		var err error
+		if net, err = nn.Load(opts.modelPath); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	wg := new(sync.WaitGroup)
+	sims := make(chan *santorini.Simulation)
+	completedSims := make(chan *santorini.Simulation)
+	for i := 0; i < opts.threadCount; i++ {
+		wg.Add(1)
+		go arena.Runner(wg, sims, completedSims)
+	}
+
+	for generation := 0; generation < opts.generations; generation++ {
+		seed := opts.seed + int64(generation*opts.games)
+		samples := selfPlay(opts, net, seed, sims, completedSims)
+
+		loss := 0.0
+		for epoch := 0; epoch < opts.epochs; epoch++ {
+			loss = net.Train(rng, samples, opts.rate)
+		}
+		if err := net.Save(opts.outPath); err != nil {
+			logrus.Errorf("Failed to save network: %s", err)
+		}
+
+		fields := logrus.Fields{
+			"generation": generation + 1,
+			"samples":    len(samples),
+			"loss":       fmt.Sprintf("%.4f", loss),
+		}
+		if opts.evalGames > 0 {
+			fields["vsRandom"] = fmt.Sprintf("%.2f", evaluate(opts, net, seed, sims, completedSims))
+		}
+		logrus.WithFields(fields).Info("Generation Complete")
+	}
+	close(sims)
+	wg.Wait()
+}
+
+// selfPlay plays a generation of games and returns the samples of the decided ones
+func selfPlay(opts *options, net *nn.Network, seed int64, sims, completedSims chan *santorini.Simulation) []nn.Sample {
+	bot := exploringBot(net, opts.epsilon)
+	go func() {
+		for i := 0; i < opts.games; i++ {
+			sims <- santorini.NewSeededSimulator(i, seed+int64(i), logrus.StandardLogger(), bot, bot)
+		}
+	}()
+
+	pb := progressbar.Default(int64(opts.games), "self-play")
+	var samples []nn.Sample
+	for i := 0; i < opts.games; i++ {
+		sim := <-completedSims
+		pb.Add(1)
+		if sim.Board.Victor == 0 {
+			continue
+		}
+		samples = append(samples, nn.Samples(sim.Start, sim.Board.Moves, sim.Board.Victor)...)
+	}
+	return samples
+}
+
+// evaluate plays the greedy network against RandomBot with both colors and returns its win rate
+func evaluate(opts *options, net *nn.Network, seed int64, sims, completedSims chan *santorini.Simulation) float64 {
+	go func() {
+		for i := 0; i < opts.evalGames; i++ {
+			sims <- santorini.NewSeededSimulator(i, seed+int64(i), logrus.StandardLogger(), arena.Seated(i, bots.NewNeuralBot(net), bots.NewRandomBot)...)
+		}
+	}()
+
+	wins := 0
+	for i := 0; i < opts.evalGames; i++ {
+		if arena.Winner(<-completedSims, 2) == 0 {
+			wins++
+		}
+	}
+	return float64(wins) / float64(opts.evalGames)
+}
+
+func exploringBot(net *nn.Network, epsilon float64) santorini.BotInitializer {
+	return func(team int, board *santorini.Board, logger *logrus.Logger) santorini.TurnSelector {
+		bot := bots.NewNeuralBot(net)(team, board, logger).(*bots.NeuralBot)
+		bot.Epsilon = epsilon
+		return bot
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"santorini/bots"
+	santorini "santorini/pkg"
+	"santorini/pkg/arena"
+	"sync"
+
+	"github.com/schollz/progressbar/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// Tune BasicBot's weights with SPSA (simultaneous perturbation stochastic approximation).
+// Every iteration perturbs all weights at once in a random direction, plays the two
+// perturbed bots against each other and steps the weights towards the winner.
+type options struct {
+	threadCount     int
+	iterations      int
+	pairs           int // Game pairs per iteration, each opening is played with both colors
+	openingPlies    int // Random turns played before the bots take over
+	seed            int64
+	a               float64 // SPSA step size
+	c               float64 // SPSA perturbation size
+	initPath        string
+	outPath         string
+	checkpointPath  string
+	checkpointEvery int
+	resume          bool
+}
+
+// checkpoint is everything needed to continue a tuning run
+type checkpoint struct {
+	Seed       int64     `json:"seed"`
+	Iteration  int       `json:"iteration"`
+	Iterations int       `json:"iterations"` // The run's length, which the step sizes are scheduled for
+	Theta      []float64 `json:"theta"`
+	Scale      []float64 `json:"scale"`
+}
+
+func main() {
+	opts := &options{}
+	flag.IntVar(&opts.threadCount, "threads", 10, "Number of threads to use")
+	flag.IntVar(&opts.iterations, "iterations", 200, "Number of SPSA iterations, when resuming the checkpoint's unless given")
+	flag.IntVar(&opts.pairs, "pairs", 16, "Game pairs played per iteration")
+	flag.IntVar(&opts.openingPlies, "opening", 4, "Random turns played before the bots take over")
+	flag.Int64Var(&opts.seed, "seed", 1, "Seed for perturbations and openings")
+	flag.Float64Var(&opts.a, "a", 0.2, "SPSA step size, as a fraction of each weight")
+	flag.Float64Var(&opts.c, "c", 0.2, "SPSA perturbation size, as a fraction of each weight")
+	flag.StringVar(&opts.initPath, "init", "", "Weights file to start from (defaults to BasicBot's weights)")
+	flag.StringVar(&opts.outPath, "out", "weights.json", "File to write the tuned weights to")
+	flag.StringVar(&opts.checkpointPath, "checkpoint", "tune.checkpoint.json", "File to save progress to")
+	flag.IntVar(&opts.checkpointEvery, "checkpoint-every", 10, "Iterations between checkpoints, 0 to only save at the end")
+	flag.BoolVar(&opts.resume, "resume", false, "Continue from the checkpoint file")
+	flag.Parse()
+
+	if opts.openingPlies%2 != 0 {
+		fmt.Println("The opening must have an even number of turns so team 1 moves first")
+		os.Exit(1)
+	}
+	if opts.checkpointEvery < 0 {
+		fmt.Println("-checkpoint-every cannot be negative")
+		os.Exit(1)
+	}
+
+	state, err := initialState(opts)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if opts.resume && !isFlagSet("iterations") {
+		opts.iterations = state.Iterations
+	}
+
+	logrus.Infof("Tuning %d weights for %d iterations (seed %d, starting at %d)", len(state.Theta), opts.iterations, state.Seed, state.Iteration)
+
+	wg := new(sync.WaitGroup)
+	sims := make(chan *santorini.Simulation)
+	completedSims := make(chan *santorini.Simulation)
+	for i := 0; i < opts.threadCount; i++ {
+		wg.Add(1)
+		go arena.Runner(wg, sims, completedSims)
+	}
+
+	pb := progressbar.Default(int64(opts.iterations))
+	pb.Add(state.Iteration)
+	for state.Iteration < opts.iterations {
+		score := step(opts, state, sims, completedSims)
+		state.Iteration++
+		pb.Describe(fmt.Sprintf("last %+.2f", score))
+		pb.Add(1)
+
+		if (opts.checkpointEvery > 0 && state.Iteration%opts.checkpointEvery == 0) || state.Iteration == opts.iterations {
+			if err := save(opts.checkpointPath, state); err != nil {
+				logrus.Errorf("Failed to save checkpoint: %s", err)
+			}
+		}
+	}
+	close(sims)
+	wg.Wait()
+
+	weights := toWeights(state.Theta)
+	if err := weights.Save(opts.outPath); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	logrus.WithFields(map[string]interface{}{
+		"iterations": state.Iteration,
+		"seed":       state.Seed,
+		"out":        opts.outPath,
+	}).Info("Tuning Complete")
+}
+
+// initialState loads the checkpoint when resuming, or starts from the initial weights
+func initialState(opts *options) (*checkpoint, error) {
+	if opts.resume {
+		data, err := os.ReadFile(opts.checkpointPath)
+		if err != nil {
+			return nil, err
+		}
+		state := &checkpoint{}
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, err
+		}
+		if len(state.Theta) != len(state.Scale) || state.Iterations <= 0 {
+			return nil, errors.New("checkpoint is corrupt")
+		}
+		if weights := bots.DefaultBasicWeights; len(state.Theta) != len(weights.Params()) {
+			return nil, errors.New("checkpoint was made for a different set of weights")
+		}
+		return state, nil
+	}
+
+	weights := bots.DefaultBasicWeights
+	if opts.initPath != "" {
+		var err error
+		if weights, err = bots.LoadBasicWeights(opts.initPath); err != nil {
+			return nil, err
+		}
+	}
+
+	state := &checkpoint{Seed: opts.seed, Iterations: opts.iterations}
+	for _, p := range weights.Params() {
+		state.Theta = append(state.Theta, float64(*p))
+		// Perturb each weight relative to its size so small weights are not drowned out
+		state.Scale = append(state.Scale, math.Max(math.Abs(float64(*p)), 10))
+	}
+	return state, nil
+}
+
+// step runs one SPSA iteration and returns the score of the positive perturbation, from -1 to 1
+func step(opts *options, state *checkpoint, sims, completedSims chan *santorini.Simulation) float64 {
+	// Every iteration has its own RNG so a resumed run makes the same choices
+	rng := rand.New(rand.NewSource(state.Seed + int64(state.Iteration)))
+	k := float64(state.Iteration + 1)
+	ak := opts.a / math.Pow(k+float64(state.Iterations)/10, 0.602)
+	ck := opts.c / math.Pow(k, 0.101)
+
+	delta := make([]float64, len(state.Theta))
+	plus := make([]float64, len(state.Theta))
+	minus := make([]float64, len(state.Theta))
+	for i := range state.Theta {
+		delta[i] = 1
+		if rng.Intn(2) == 0 {
+			delta[i] = -1
+		}
+		plus[i] = state.Theta[i] + ck*delta[i]*state.Scale[i]
+		minus[i] = state.Theta[i] - ck*delta[i]*state.Scale[i]
+	}
+	plusBot := bots.NewWeightedBasicBot(toWeights(plus))
+	minusBot := bots.NewWeightedBasicBot(toWeights(minus))
+
+	openings := make([]*santorini.Board, opts.pairs)
+	for i := range openings {
+		openings[i] = randomOpening(rng, opts.openingPlies)
+	}
+	seeds := make([]int64, opts.pairs*2)
+	for i := range seeds {
+		seeds[i] = rng.Int63()
+	}
+
+	go func() {
+		for i := 0; i < opts.pairs*2; i++ {
+			// Both bots play each opening from both seats
+			sims <- santorini.NewPositionSimulator(i, seeds[i], openings[i/2], logrus.StandardLogger(), arena.Seated(i, plusBot, minusBot)...)
+		}
+	}()
+
+	score := 0
+	for i := 0; i < opts.pairs*2; i++ {
+		switch arena.Winner(<-completedSims, 2) {
+		case 0:
+			score++
+		case 1:
+			score--
+		}
+	}
+	result := float64(score) / float64(opts.pairs*2)
+
+	for i := range state.Theta {
+		state.Theta[i] += ak * result * delta[i] * state.Scale[i]
+	}
+	return result
+}
+
+// randomOpening plays random turns from the default position, alternating teams, and returns the
+// position the bots take over from
+func randomOpening(rng *rand.Rand, plies int) *santorini.Board {
+	for {
+		board := santorini.DefaultPosition(2)
+		turns := 0
+		for i := 0; i < plies; i++ {
+			candidates := board.GetValidTurns(i%2 + 1)
+			if len(candidates) == 0 {
+				break
+			}
+			if board.PlayTurn(candidates[rng.Intn(len(candidates))]) {
+				break
+			}
+			turns++
+		}
+		// Retry openings that ended the game before the bots got to play
+		if turns == plies {
+			return board
+		}
+	}
+}
+
+func toWeights(theta []float64) bots.BasicWeights {
+	weights := bots.BasicWeights{}
+	for i, p := range weights.Params() {
+		*p = int(math.Round(theta[i]))
+	}
+	return weights
+}
+
+// save writes the checkpoint to a temporary file first, so an interruption never leaves half a checkpoint
+func save(path string, state *checkpoint) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// isFlagSet returns true if the flag was given on the command line
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
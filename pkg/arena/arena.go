@@ -0,0 +1,73 @@
+/* Package arena plays many games between bots.
+ *
+ * Games are run on a pool of runners, and game number n seats the bots in the n-th order they can
+ * be seated in, so a run of games goes through every order before repeating one. With two bots
+ * they swap seats every game, so bot 0 goes first in even games.
+ */
+package arena
+
+import (
+	santorini "santorini/pkg"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Runner plays the games it receives until sims is closed, sending each finished game to results
+func Runner(wg *sync.WaitGroup, sims chan *santorini.Simulation, results chan *santorini.Simulation) {
+	defer wg.Done()
+	defer logrus.Debug("Runner finished")
+	for sim := range sims {
+		sim.Run()
+		results <- sim
+	}
+}
+
+// Seat returns the bot that plays team index (from 0) in game number n. Games go through every order
+// of the bots in turn, so each bot plays from every seat against every seating of the others
+func Seat(team, n, bots int) int {
+	return Seating(n, bots)[team]
+}
+
+// Seating returns the bot playing each team in game number n, the n%bots!-th order of the bots
+func Seating(n, bots int) []int {
+	remaining := make([]int, bots)
+	for i := range remaining {
+		remaining[i] = i
+	}
+	seats := make([]int, 0, bots)
+	k := n % Orderings(bots)
+	for i := bots - 1; i >= 0; i-- {
+		j := k / Orderings(i)
+		k %= Orderings(i)
+		seats = append(seats, remaining[j])
+		remaining = append(remaining[:j], remaining[j+1:]...)
+	}
+	return seats
+}
+
+// Orderings returns the number of orders the bots can be seated in
+func Orderings(bots int) int {
+	n := 1
+	for i := 2; i <= bots; i++ {
+		n *= i
+	}
+	return n
+}
+
+// Seated returns the bots in the teams they play in game number n
+func Seated(n int, bots ...santorini.BotInitializer) []santorini.BotInitializer {
+	teams := make([]santorini.BotInitializer, len(bots))
+	for team := range teams {
+		teams[team] = bots[Seat(team, n, len(bots))]
+	}
+	return teams
+}
+
+// Winner returns which of the bots given to Seated won the game, or -1 if no team won
+func Winner(sim *santorini.Simulation, bots int) int {
+	if sim.Board.Victor == 0 {
+		return -1
+	}
+	return Seat(sim.Board.Victor-1, sim.Number, bots)
+}
@@ -0,0 +1,34 @@
+package arena
+
+import (
+	santorini "santorini/pkg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeating(t *testing.T) {
+	// Two bots swap seats every game
+	assert.Equal(t, []int{0, 1}, Seating(0, 2))
+	assert.Equal(t, []int{1, 0}, Seating(1, 2))
+	assert.Equal(t, []int{0, 1}, Seating(2, 2))
+
+	// Every order of three bots is played once before any is repeated
+	seen := make(map[[3]int]bool)
+	for n := 0; n < 6; n++ {
+		s := Seating(n, 3)
+		seen[[3]int{s[0], s[1], s[2]}] = true
+	}
+	assert.Len(t, seen, 6)
+	assert.Equal(t, Seating(1, 3), Seating(7, 3))
+	assert.Equal(t, 24, Orderings(4))
+	assert.Equal(t, 3, Seat(0, 23, 4))
+}
+
+func TestWinner(t *testing.T) {
+	// Game 3 is seated 1, 2, 0
+	sim := &santorini.Simulation{Number: 3, Board: &santorini.Board{Victor: 3}}
+	assert.Equal(t, 0, Winner(sim, 3))
+	sim.Board.Victor = 0
+	assert.Equal(t, -1, Winner(sim, 3))
+}
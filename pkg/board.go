@@ -51,6 +51,26 @@ func NewBoard(options ...func(*Board)) *Board {
 	return board
 }
 
+// WithSize sets the width and height of a new board
+func WithSize(size int) func(*Board) {
+	return func(board *Board) {
+		board.Size = size
+	}
+}
+
+// Clone returns a copy of the board that can be changed without affecting the original
+func (board Board) Clone() *Board {
+	clone := board
+	clone.Tiles = board.GetTiles()
+	clone.Teams = make(map[int]bool, len(board.Teams))
+	for team, playing := range board.Teams {
+		clone.Teams[team] = playing
+	}
+	clone.Moves = make([]Turn, len(board.Moves))
+	copy(clone.Moves, board.Moves)
+	return &clone
+}
+
 func (board Board) GetTiles() (tiles []Tile) {
 	tiles = make([]Tile, len(board.Tiles))
 	copy(tiles, board.Tiles)
@@ -225,6 +245,37 @@ func (board *Board) PlayTurn(turn Turn) (gameover bool) {
 	return false
 }
 
+// Eliminate takes a team that cannot move out of the game, removing its workers from the board
+func (board *Board) Eliminate(team int) {
+	for _, tile := range board.Tiles {
+		if tile.team == team {
+			tile.team = 0
+			tile.worker = 0
+			board.setTile(tile)
+		}
+	}
+	board.Teams[team] = false
+}
+
+// NextTeam returns the team that moves after team, skipping teams that can no longer play
+func (board *Board) NextTeam(team int) int {
+	// Positions may leave out teams that were knocked out, so count up to the last team
+	teams := 0
+	for t := range board.Teams {
+		if t > teams {
+			teams = t
+		}
+	}
+	next := team
+	for i := 0; i < teams; i++ {
+		next = next%teams + 1
+		if board.Teams[next] {
+			return next
+		}
+	}
+	return team%teams + 1
+}
+
 // PlaceWorker on the board, should be called before any turns are made
 func (board *Board) PlaceWorker(team, worker, x, y int) {
 	workerTile := board.GetTile(x, y)
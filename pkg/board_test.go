@@ -55,3 +55,17 @@ func TestPlayTurn(t *testing.T) {
 	assert.Equal(t, 2, newTile.y)
 	assert.Equal(t, 0, newTile.height)
 }
+
+func TestEliminate(t *testing.T) {
+	board := DefaultPosition(3)
+	board.Eliminate(2)
+
+	assert.False(t, board.Teams[2])
+	assert.True(t, board.Teams[3])
+	for _, tile := range board.Tiles {
+		assert.NotEqual(t, 2, tile.team)
+	}
+	// Team 2 no longer takes turns
+	assert.Equal(t, 3, board.NextTeam(1))
+	assert.Equal(t, 1, board.NextTeam(3))
+}
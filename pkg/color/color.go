@@ -45,5 +45,11 @@ func GetWorkerColor(team, number int) string {
 	if team == 3 && number == 2 {
 		return Green
 	}
+	if team == 4 && number == 1 {
+		return White
+	}
+	if team == 4 && number == 2 {
+		return Gray
+	}
 	return White
 }
@@ -0,0 +1,235 @@
+package engine
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	santorini "santorini/pkg"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTimeout = errors.New("engine timed out")
+	ErrExited  = errors.New("engine exited")
+)
+
+// Client controls an engine over the protocol
+type Client struct {
+	Name    string
+	Author  string
+	Options []Option
+
+	// How long to wait for the engine to respond to anything other than go
+	Timeout time.Duration
+	// Called with every info line the engine sends while searching
+	OnInfo func(info string)
+
+	w     io.Writer
+	lines chan string
+	cmd   *exec.Cmd
+	// Searches that timed out, whose bestmove is still to come and must not answer a later search
+	late int
+}
+
+// Start launches an engine and performs the handshake
+func Start(command string, args ...string) (*Client, error) {
+	cmd := exec.Command(command, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	c, err := connect(stdout, stdin, cmd)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Connect performs the handshake with an engine that is already running
+func Connect(r io.Reader, w io.Writer) (*Client, error) {
+	return connect(r, w, nil)
+}
+
+func connect(r io.Reader, w io.Writer, cmd *exec.Cmd) (*Client, error) {
+	c := &Client{
+		Timeout: 10 * time.Second,
+		w:       w,
+		lines:   make(chan string),
+		cmd:     cmd,
+	}
+	go c.read(r)
+
+	if err := c.send("sep"); err != nil {
+		return nil, err
+	}
+	for {
+		line, err := c.readLine(c.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("handshake failed: %w", err)
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case fields[0] == "sepok":
+			return c, nil
+		case fields[0] == "id" && len(fields) > 2 && fields[1] == "name":
+			c.Name = strings.Join(fields[2:], " ")
+		case fields[0] == "id" && len(fields) > 2 && fields[1] == "author":
+			c.Author = strings.Join(fields[2:], " ")
+		case fields[0] == "option":
+			c.Options = append(c.Options, parseOption(fields[1:]))
+		}
+	}
+}
+
+// read passes every line from the engine to the lines channel, closing it when the engine exits
+func (c *Client) read(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		c.lines <- strings.TrimSpace(scanner.Text())
+	}
+	close(c.lines)
+}
+
+func (c *Client) readLine(timeout time.Duration) (string, error) {
+	select {
+	case line, ok := <-c.lines:
+		if !ok {
+			return "", ErrExited
+		}
+		return line, nil
+	case <-time.After(timeout):
+		return "", ErrTimeout
+	}
+}
+
+func (c *Client) send(format string, args ...interface{}) error {
+	_, err := fmt.Fprintf(c.w, format+"\n", args...)
+	return err
+}
+
+// SetOption changes a setting of the engine
+func (c *Client) SetOption(name, value string) error {
+	return c.send("setoption name %s value %s", name, value)
+}
+
+// NewGame tells the engine the next position is from a different game
+func (c *Client) NewGame() error {
+	return c.send("newgame")
+}
+
+// IsReady waits for the engine to finish processing every command sent so far
+func (c *Client) IsReady() error {
+	if err := c.send("isready"); err != nil {
+		return err
+	}
+	for {
+		line, err := c.readLine(c.Timeout)
+		if err != nil {
+			return err
+		}
+		if line == "readyok" {
+			return nil
+		}
+	}
+}
+
+// BestMove asks the engine for the turn the team should take. A nil turn means the engine found no moves.
+// The engine is told to stop once moveTime has passed, and is given Timeout more to respond. When it
+// answers after that, its answer is skipped by the next call
+func (c *Client) BestMove(board *santorini.Board, team int, moveTime time.Duration) (*santorini.Turn, error) {
+	if err := c.send("position notation %s", board.Notation(team)); err != nil {
+		return nil, err
+	}
+	if err := c.send("go movetime %d", moveTime.Milliseconds()); err != nil {
+		return nil, err
+	}
+
+	// Remind the engine to stop shortly after the movetime, then give up if it still has not answered
+	stopAt := time.Now().Add(moveTime + c.Timeout/10)
+	deadline := stopAt.Add(c.Timeout)
+	stopped := false
+	for {
+		wait := time.Until(deadline)
+		if !stopped {
+			wait = time.Until(stopAt)
+		}
+		line, err := c.readLine(wait)
+		if err == ErrTimeout && !stopped {
+			stopped = true
+			c.send("stop")
+			continue
+		} else if err == ErrTimeout {
+			c.late++
+			return nil, err
+		} else if err != nil {
+			return nil, err
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "info":
+			if c.OnInfo != nil {
+				c.OnInfo(strings.TrimSpace(strings.TrimPrefix(line, "info")))
+			}
+		case "bestmove":
+			if c.late > 0 {
+				// The answer to a search that timed out
+				c.late--
+				continue
+			}
+			if len(fields) < 2 {
+				return nil, errors.New("bestmove is missing a turn")
+			}
+			if fields[1] == "none" {
+				return nil, nil
+			}
+			turn, err := board.ParseTurn(fields[1])
+			if err != nil {
+				return nil, err
+			}
+			return &turn, nil
+		}
+	}
+}
+
+// Close tells the engine to quit, killing it if it does not exit in time
+func (c *Client) Close() error {
+	c.send("quit")
+	if closer, ok := c.w.(io.Closer); ok {
+		closer.Close()
+	}
+	if c.cmd == nil {
+		return nil
+	}
+	exited := make(chan error, 1)
+	go func() {
+		// Drain any remaining output so the engine is never blocked writing
+		for range c.lines {
+		}
+		exited <- c.cmd.Wait()
+	}()
+	select {
+	case err := <-exited:
+		return err
+	case <-time.After(c.Timeout):
+		c.cmd.Process.Kill()
+		return <-exited
+	}
+}
@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"bufio"
+	"io"
+	santorini "santorini/pkg"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// scriptedEngine answers each command it receives with the matching reply
+func scriptedEngine(replies map[string][]string) (io.Reader, io.Writer) {
+	cmdR, cmdW := io.Pipe()
+	outR, outW := io.Pipe()
+	go func() {
+		scanner := bufio.NewScanner(cmdR)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			for _, reply := range replies[fields[0]] {
+				io.WriteString(outW, reply+"\n")
+			}
+		}
+		outW.Close()
+	}()
+	return outR, cmdW
+}
+
+func TestClient(t *testing.T) {
+	r, w := scriptedEngine(map[string][]string{
+		"sep":     {"id name Scripted", "id author Tests", "option name depth type int default 2", "sepok"},
+		"isready": {"readyok"},
+		"go":      {"info depth 1 score 5", "bestmove 1.1:c3d4"},
+	})
+	client, err := Connect(r, w)
+	assert.NoError(t, err)
+	assert.Equal(t, "Scripted", client.Name)
+	assert.Equal(t, "Tests", client.Author)
+	assert.Equal(t, []Option{{Name: "depth", Type: "int", Default: "2"}}, client.Options)
+	assert.NoError(t, client.IsReady())
+
+	infos := []string{}
+	client.OnInfo = func(info string) {
+		infos = append(infos, info)
+	}
+	board := santorini.DefaultPosition(2)
+	turn, err := client.BestMove(board, 1, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.1:c3d4", turn.Notation())
+	assert.Equal(t, []string{"depth 1 score 5"}, infos)
+}
+
+func TestClientTimeout(t *testing.T) {
+	r, w := scriptedEngine(map[string][]string{
+		"sep":  {"sepok"},
+		"stop": {"bestmove none"},
+	})
+	client, err := Connect(r, w)
+	assert.NoError(t, err)
+	client.Timeout = 100 * time.Millisecond
+
+	// The engine only answers once it is told to stop
+	turn, err := client.BestMove(santorini.DefaultPosition(2), 1, 10*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Nil(t, turn)
+}
+
+func TestClientLateAnswer(t *testing.T) {
+	cmdR, w := io.Pipe()
+	r, outW := io.Pipe()
+	go func() {
+		searches := 0
+		scanner := bufio.NewScanner(cmdR)
+		for scanner.Scan() {
+			switch scanner.Text() {
+			case "sep":
+				io.WriteString(outW, "sepok\n")
+			case "go movetime 10":
+				// The first search only answers when the second one starts
+				if searches++; searches == 2 {
+					io.WriteString(outW, "bestmove 1.1:c3c4\nbestmove 1.1:c3d4\n")
+				}
+			}
+		}
+		outW.Close()
+	}()
+	client, err := Connect(r, w)
+	assert.NoError(t, err)
+	client.Timeout = 50 * time.Millisecond
+
+	board := santorini.DefaultPosition(2)
+	_, err = client.BestMove(board, 1, 10*time.Millisecond)
+	assert.Equal(t, ErrTimeout, err)
+	turn, err := client.BestMove(board, 1, 10*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.1:c3d4", turn.Notation())
+}
@@ -0,0 +1,63 @@
+/* Package engine implements a line based text protocol for Santorini engines, similar to UCI for chess.
+ *
+ * An engine is any program that reads commands on stdin and writes responses on stdout, one per line.
+ * Positions and turns are written in the notation described in the santorini package.
+ *
+ *  Controller -> Engine                             Engine -> Controller
+ *  sep                                              id name <name>
+ *                                                   id author <author>
+ *                                                   option name <name> type <int|float|bool|string> default <value>
+ *                                                   sepok
+ *  isready                                          readyok
+ *  setoption name <name> value <value>
+ *  newgame
+ *  position startpos [teams <n>] [moves <turn> ...]
+ *  position notation <heights> <workers> <team> [moves <turn> ...]
+ *  go [movetime <ms>]                               info <key> <value> ... (any number of lines)
+ *                                                   bestmove <turn>|none
+ *  stop                                             (sends bestmove early)
+ *  quit
+ *
+ * The engine plays for the team that moves next in the position. Unknown commands are ignored. Once
+ * the movetime has passed or stop is received, the engine sends the best turn it has found so far.
+ * Bots that cannot be interrupted answer when their search completes. isready is answered at once, even
+ * during a search, and quit stops the search before the engine exits.
+ */
+package engine
+
+import (
+	"strings"
+)
+
+// Option is a setting an engine accepts through setoption
+type Option struct {
+	Name    string
+	Type    string
+	Default string
+}
+
+// String returns the option as it is sent during the handshake
+func (o Option) String() string {
+	return "option name " + o.Name + " type " + o.Type + " default " + o.Default
+}
+
+// parseOption reads the fields of an option line, without the leading "option"
+func parseOption(fields []string) (o Option) {
+	key := ""
+	for _, field := range fields {
+		switch field {
+		case "name", "type", "default":
+			key = field
+			continue
+		}
+		switch key {
+		case "name":
+			o.Name = strings.TrimSpace(o.Name + " " + field)
+		case "type":
+			o.Type = field
+		case "default":
+			o.Default = strings.TrimSpace(o.Default + " " + field)
+		}
+	}
+	return
+}
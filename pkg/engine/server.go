@@ -0,0 +1,260 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	santorini "santorini/pkg"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Server exposes a bot as an engine
+type Server struct {
+	Bot     santorini.BotInitializer
+	Name    string
+	Author  string
+	Options []Option
+	// Called for setoption commands
+	OnOption func(name, value string) error
+
+	logger *logrus.Logger
+	out    io.Writer
+	outMu  sync.Mutex
+
+	board    *santorini.Board
+	team     int
+	searches sync.WaitGroup
+	// The bot searching, so it can be stopped
+	searchMu  sync.Mutex
+	searching santorini.TurnSelector
+}
+
+// NewServer creates a server for the bot, named after the bot unless Name is changed
+func NewServer(bot santorini.BotInitializer, logger *logrus.Logger) *Server {
+	return &Server{
+		Bot:    bot,
+		Name:   bot(0, &santorini.Board{}, logger).Name(),
+		logger: logger,
+	}
+}
+
+// Serve reads commands from r and writes responses to w until quit is received or r is closed. A
+// search in progress is stopped on quit, and finishes before Serve returns
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.out = w
+	s.board, s.team = santorini.DefaultPosition(2), 1
+	defer s.searches.Wait()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "sep":
+			s.send("id name %s", s.Name)
+			if s.Author != "" {
+				s.send("id author %s", s.Author)
+			}
+			for _, o := range s.Options {
+				s.send("%s", o)
+			}
+			s.send("sepok")
+		case "isready":
+			// Answered during a search too, so stop can still be read
+			s.send("readyok")
+		case "setoption":
+			s.searches.Wait()
+			s.setOption(fields[1:])
+		case "newgame":
+			s.searches.Wait()
+			s.board, s.team = santorini.DefaultPosition(2), 1
+		case "position":
+			s.searches.Wait()
+			board, team, err := parsePosition(fields[1:])
+			if err != nil {
+				s.send("info string error: %s", err)
+				continue
+			}
+			s.board, s.team = board, team
+		case "go":
+			s.searches.Wait()
+			moveTime, err := parseGo(fields[1:])
+			if err != nil {
+				s.send("info string error: %s", err)
+				continue
+			}
+			bot := s.Bot(s.team, s.board, s.logger)
+			s.searchMu.Lock()
+			s.searching = bot
+			s.searchMu.Unlock()
+			s.searches.Add(1)
+			go s.search(bot, s.board, moveTime)
+		case "stop":
+			s.stop()
+		case "quit":
+			s.stop()
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *Server) send(format string, args ...interface{}) {
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+	fmt.Fprintf(s.out, format+"\n", args...)
+}
+
+func (s *Server) setOption(fields []string) {
+	name, value := "", ""
+	for i := 0; i < len(fields)-1; i++ {
+		if fields[i] == "name" {
+			name = fields[i+1]
+		} else if fields[i] == "value" {
+			value = strings.Join(fields[i+1:], " ")
+		}
+	}
+	if s.OnOption == nil {
+		s.send("info string error: unknown option %s", name)
+		return
+	}
+	if err := s.OnOption(name, value); err != nil {
+		s.send("info string error: %s", err)
+	}
+}
+
+// search asks the bot for its turn, stopping it once moveTime has passed unless moveTime is 0
+func (s *Server) search(bot santorini.TurnSelector, board *santorini.Board, moveTime time.Duration) {
+	defer s.searches.Done()
+	defer func() {
+		s.searchMu.Lock()
+		s.searching = nil
+		s.searchMu.Unlock()
+	}()
+	defer func() {
+		if err := recover(); err != nil {
+			s.send("info string error: %s", err)
+			s.send("bestmove none")
+		}
+	}()
+	if board.IsOver {
+		s.send("bestmove none")
+		return
+	}
+	if moveTime > 0 {
+		timer := time.AfterFunc(moveTime, func() { interrupt(bot) })
+		defer timer.Stop()
+	}
+	turn := bot.SelectTurn()
+	if turn == nil {
+		s.send("bestmove none")
+		return
+	}
+	s.send("bestmove %s", turn.Notation())
+}
+
+// stop makes the search in progress answer early
+func (s *Server) stop() {
+	s.searchMu.Lock()
+	defer s.searchMu.Unlock()
+	interrupt(s.searching)
+}
+
+// interrupt the bot if it can be, other bots answer when their search completes
+func interrupt(bot santorini.TurnSelector) {
+	if i, ok := bot.(santorini.Interrupter); ok {
+		i.Interrupt()
+	}
+}
+
+// parseGo reads the arguments of a go command, returning the movetime or 0 for no limit
+func parseGo(fields []string) (time.Duration, error) {
+	if len(fields) == 0 {
+		return 0, nil
+	}
+	if len(fields) != 2 || fields[0] != "movetime" {
+		return 0, fmt.Errorf("unexpected %q", strings.Join(fields, " "))
+	}
+	ms, err := strconv.Atoi(fields[1])
+	if err != nil || ms < 0 {
+		return 0, fmt.Errorf("invalid movetime %q", fields[1])
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// parsePosition reads the arguments of a position command, returning the board and the team to move next
+func parsePosition(fields []string) (*santorini.Board, int, error) {
+	if len(fields) == 0 {
+		return nil, 0, fmt.Errorf("position is empty")
+	}
+
+	var board *santorini.Board
+	var team int
+	switch fields[0] {
+	case "startpos":
+		teams := 2
+		fields = fields[1:]
+		if len(fields) > 1 && fields[0] == "teams" {
+			n, err := strconv.Atoi(fields[1])
+			if err != nil || n < 1 || n > 4 {
+				return nil, 0, fmt.Errorf("invalid team count %q", fields[1])
+			}
+			teams = n
+			fields = fields[2:]
+		}
+		board, team = santorini.DefaultPosition(teams), 1
+	case "notation":
+		if len(fields) < 4 {
+			return nil, 0, fmt.Errorf("notation needs 3 fields")
+		}
+		var err error
+		if board, team, err = santorini.ParseNotation(strings.Join(fields[1:4], " ")); err != nil {
+			return nil, 0, err
+		}
+		fields = fields[4:]
+	default:
+		return nil, 0, fmt.Errorf("unknown position %q", fields[0])
+	}
+
+	if len(fields) == 0 {
+		return board, team, nil
+	}
+	if fields[0] != "moves" {
+		return nil, 0, fmt.Errorf("unexpected %q", fields[0])
+	}
+	for _, notation := range fields[1:] {
+		turn, err := board.ParseTurn(notation)
+		if err != nil {
+			return nil, 0, err
+		}
+		if board.IsOver {
+			return nil, 0, fmt.Errorf("the game is over before %s", notation)
+		}
+		if turn.Team != team {
+			return nil, 0, fmt.Errorf("it is not team %d's turn", turn.Team)
+		}
+		if !isValid(board, turn) {
+			return nil, 0, fmt.Errorf("illegal turn %s", notation)
+		}
+		board.PlayTurn(turn)
+		team = board.NextTeam(turn.Team)
+	}
+	return board, team, nil
+}
+
+// isValid returns true if the turn is one of the turns its team may take
+func isValid(board *santorini.Board, turn santorini.Turn) bool {
+	for _, candidate := range board.GetValidTurns(turn.Team) {
+		if candidate == turn {
+			return true
+		}
+	}
+	return false
+}
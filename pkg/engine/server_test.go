@@ -0,0 +1,22 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePosition(t *testing.T) {
+	board, team, err := parsePosition([]string{"startpos", "teams", "4"})
+	assert.NoError(t, err)
+	assert.Len(t, board.Teams, 4)
+	assert.Equal(t, 1, team)
+
+	_, _, err = parsePosition([]string{"startpos", "teams", "5"})
+	assert.Error(t, err)
+
+	// Team 2 has been knocked out, so team 3 moves after team 1
+	_, team, err = parsePosition([]string{"notation", "00000/00000/00000/00000/00000", "1.1:a1,1.2:e5,3.1:a5,3.2:e1", "1", "moves", "1.1:b2c3"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, team)
+}
@@ -0,0 +1,52 @@
+package santorini
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Explainer is implemented by bots that can say why they chose their last turn.
+// Explain must be called before the turn is played, and returns nil if there is nothing to explain
+type Explainer interface {
+	Explain(candidates int) *Explanation
+}
+
+// Explanation is the rationale behind a bot's last turn
+type Explanation struct {
+	Reason     string      // Why the turn was chosen, such as "ranked" or "blocking a win"
+	Candidates []Candidate // The best turns the bot considered, best first
+	PV         []Turn      // Principal variation, the turns a search bot expects both teams to play
+}
+
+// Candidate is a turn a bot considered and how it scored it
+type Candidate struct {
+	Turn     Turn
+	Score    float64
+	Features map[string]float64 // How much each part of the evaluation added to the score
+}
+
+// String returns the explanation over several lines
+func (e *Explanation) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Reason)
+	for i, c := range e.Candidates {
+		fmt.Fprintf(&sb, "\n  %d. %s %.3f", i+1, c.Turn.Notation(), c.Score)
+		names := make([]string, 0, len(c.Features))
+		for name := range c.Features {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&sb, " %s=%g", name, c.Features[name])
+		}
+	}
+	if len(e.PV) > 0 {
+		pv := make([]string, len(e.PV))
+		for i, turn := range e.PV {
+			pv[i] = turn.Notation()
+		}
+		fmt.Fprintf(&sb, "\n  pv %s", strings.Join(pv, " "))
+	}
+	return sb.String()
+}
@@ -0,0 +1,58 @@
+package nn
+
+import (
+	santorini "santorini/pkg"
+)
+
+// Planes is the number of inputs for each tile: one for each height from 0 to 4,
+// one for the team's workers and one for every enemy worker
+const Planes = 7
+
+// PolicySize is the number of policy outputs, one for every worker, move direction and build direction
+const PolicySize = 2 * 8 * 8
+
+// directions lists the neighbors of a tile clockwise from north
+var directions = [8][2]int{{0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}}
+
+func direction(dx, dy int) int {
+	for i, d := range directions {
+		if d[0] == dx && d[1] == dy {
+			return i
+		}
+	}
+	return -1
+}
+
+// Encode the board from the point of view of the team
+func Encode(board *santorini.Board, team int) []float64 {
+	tiles := board.Size * board.Size
+	input := make([]float64, Planes*tiles)
+	for _, tile := range board.Tiles {
+		index := tile.GetY()*board.Size + tile.GetX()
+		input[tile.GetHeight()*tiles+index] = 1
+		if tile.GetTeam() == team {
+			input[5*tiles+index] = 1
+		} else if tile.IsOccupied() {
+			input[6*tiles+index] = 1
+		}
+	}
+	return input
+}
+
+// PolicyIndex returns the policy output for a turn on the board before it is played, or -1 if there is none
+func PolicyIndex(board *santorini.Board, turn santorini.Turn) int {
+	if turn.Worker < 1 || turn.Worker > 2 {
+		return -1
+	}
+	from := board.GetWorkerTile(turn.Team, turn.Worker)
+	move := direction(turn.MoveTo.GetX()-from.GetX(), turn.MoveTo.GetY()-from.GetY())
+	build := direction(turn.Build.GetX()-turn.MoveTo.GetX(), turn.Build.GetY()-turn.MoveTo.GetY())
+	if move < 0 {
+		return -1
+	}
+	if build < 0 {
+		// Winning turns do not build, any direction will do
+		build = 0
+	}
+	return (turn.Worker-1)*64 + move*8 + build
+}
@@ -0,0 +1,140 @@
+/* Package nn is a small neural network that evaluates Santorini positions on the CPU.
+ *
+ * The network has one hidden layer shared by two heads. The value head predicts the result of
+ * the game for the team to move, from -1 (loss) to 1 (win). The policy head scores every turn
+ * the team could take, see PolicyIndex.
+ */
+package nn
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+)
+
+type Network struct {
+	Size    int // Board size the network was made for
+	Inputs  int
+	Hidden  int
+	Outputs int // Number of policy outputs
+
+	W1 [][]float64 // Hidden x Inputs
+	B1 []float64
+	WV []float64 // Value weights for each hidden unit
+	BV float64
+	WP [][]float64 // Outputs x Hidden
+	BP []float64
+}
+
+// New creates a network for boards of the given size, with random weights
+func New(rng *rand.Rand, size, hidden int) *Network {
+	n := &Network{
+		Size:    size,
+		Inputs:  Planes * size * size,
+		Hidden:  hidden,
+		Outputs: PolicySize,
+	}
+	n.W1 = randomMatrix(rng, hidden, n.Inputs)
+	n.B1 = make([]float64, hidden)
+	n.WV = randomMatrix(rng, 1, hidden)[0]
+	n.WP = randomMatrix(rng, n.Outputs, hidden)
+	n.BP = make([]float64, n.Outputs)
+	return n
+}
+
+func randomMatrix(rng *rand.Rand, rows, cols int) [][]float64 {
+	// Xavier initialization keeps the activations from exploding or vanishing
+	scale := math.Sqrt(2 / float64(rows+cols))
+	m := make([][]float64, rows)
+	for i := range m {
+		m[i] = make([]float64, cols)
+		for j := range m[i] {
+			m[i][j] = rng.NormFloat64() * scale
+		}
+	}
+	return m
+}
+
+// activations are kept from the forward pass so they can be used for training
+type activations struct {
+	hidden []float64
+	value  float64
+	policy []float64 // Probabilities after softmax
+}
+
+func (n *Network) forward(input []float64) activations {
+	a := activations{
+		hidden: make([]float64, n.Hidden),
+		policy: make([]float64, n.Outputs),
+	}
+	for i := range a.hidden {
+		sum := n.B1[i]
+		for j, x := range input {
+			if x != 0 {
+				sum += n.W1[i][j] * x
+			}
+		}
+		// ReLU
+		if sum > 0 {
+			a.hidden[i] = sum
+		}
+	}
+
+	value := n.BV
+	for i, h := range a.hidden {
+		value += n.WV[i] * h
+	}
+	a.value = math.Tanh(value)
+
+	max := math.Inf(-1)
+	for k := range a.policy {
+		sum := n.BP[k]
+		for i, h := range a.hidden {
+			sum += n.WP[k][i] * h
+		}
+		a.policy[k] = sum
+		max = math.Max(max, sum)
+	}
+	total := 0.0
+	for k := range a.policy {
+		a.policy[k] = math.Exp(a.policy[k] - max)
+		total += a.policy[k]
+	}
+	for k := range a.policy {
+		a.policy[k] /= total
+	}
+	return a
+}
+
+// Evaluate returns the value of an encoded position, and the probability of every policy output
+func (n *Network) Evaluate(input []float64) (float64, []float64) {
+	a := n.forward(input)
+	return a.value, a.policy
+}
+
+// Load a network saved with Save
+func Load(path string) (*Network, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	n := &Network{}
+	if err := json.Unmarshal(data, n); err != nil {
+		return nil, err
+	}
+	if len(n.W1) != n.Hidden || len(n.WP) != n.Outputs || n.Inputs != Planes*n.Size*n.Size {
+		return nil, fmt.Errorf("%s is not a valid network", path)
+	}
+	return n, nil
+}
+
+// Save the network to a JSON checkpoint file
+func (n *Network) Save(path string) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
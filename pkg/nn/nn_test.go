@@ -0,0 +1,57 @@
+package nn
+
+import (
+	"math/rand"
+	"path/filepath"
+	santorini "santorini/pkg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyIndexUnique(t *testing.T) {
+	board := santorini.DefaultPosition(2)
+	seen := make(map[int]bool)
+	for _, turn := range board.GetValidTurns(1) {
+		index := PolicyIndex(board, turn)
+		assert.True(t, index >= 0 && index < PolicySize)
+		assert.False(t, seen[index], "duplicate index for %s", turn.Notation())
+		seen[index] = true
+	}
+}
+
+func TestTrainReducesLoss(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	board := santorini.DefaultPosition(2)
+	var moves []santorini.Turn
+	for i := 0; i < 6; i++ {
+		team := i%2 + 1
+		turns := board.GetValidTurns(team)
+		turn := turns[rng.Intn(len(turns))]
+		moves = append(moves, turn)
+		board.PlayTurn(turn)
+	}
+	samples := Samples(santorini.DefaultPosition(2), moves, 1)
+	assert.Len(t, samples, 6)
+
+	n := New(rng, 5, 16)
+	first := n.Train(rng, samples, 0.01)
+	var last float64
+	for i := 0; i < 50; i++ {
+		last = n.Train(rng, samples, 0.01)
+	}
+	assert.Less(t, last, first/2)
+
+	// The value head learns who won
+	value, _ := n.Evaluate(samples[0].Input)
+	assert.Greater(t, value, 0.5)
+}
+
+func TestSaveLoad(t *testing.T) {
+	n := New(rand.New(rand.NewSource(1)), 5, 8)
+	path := filepath.Join(t.TempDir(), "net.json")
+	assert.NoError(t, n.Save(path))
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, n, loaded)
+}
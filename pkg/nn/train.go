@@ -0,0 +1,93 @@
+package nn
+
+import (
+	"math"
+	"math/rand"
+	santorini "santorini/pkg"
+)
+
+// Sample is a position from a game and what happened next
+type Sample struct {
+	Input  []float64
+	Value  float64 // 1 if the team to move went on to win, -1 if it lost
+	Policy int     // The turn that was played, -1 to leave the policy untrained
+}
+
+// Samples replays a finished game and returns a sample for every turn in it
+func Samples(start *santorini.Board, moves []santorini.Turn, victor int) []Sample {
+	board := start.Clone()
+	samples := make([]Sample, 0, len(moves))
+	for _, turn := range moves {
+		value := -1.0
+		if turn.Team == victor {
+			value = 1
+		}
+		samples = append(samples, Sample{
+			Input:  Encode(board, turn.Team),
+			Value:  value,
+			Policy: PolicyIndex(board, turn),
+		})
+		board.PlayTurn(turn)
+	}
+	return samples
+}
+
+// Train runs stochastic gradient descent over the samples in a random order and returns the average loss
+func (n *Network) Train(rng *rand.Rand, samples []Sample, rate float64) float64 {
+	total := 0.0
+	dHidden := make([]float64, n.Hidden)
+	for _, i := range rng.Perm(len(samples)) {
+		total += n.train(samples[i], rate, dHidden)
+	}
+	if len(samples) == 0 {
+		return 0
+	}
+	return total / float64(len(samples))
+}
+
+// train updates the weights for one sample and returns its loss
+func (n *Network) train(s Sample, rate float64, dHidden []float64) float64 {
+	a := n.forward(s.Input)
+
+	// Squared error of the value, through tanh
+	loss := (a.value - s.Value) * (a.value - s.Value)
+	dValue := 2 * (a.value - s.Value) * (1 - a.value*a.value)
+
+	for i := range dHidden {
+		dHidden[i] = dValue * n.WV[i]
+	}
+	n.BV -= rate * dValue
+	for i, h := range a.hidden {
+		n.WV[i] -= rate * dValue * h
+	}
+
+	// Cross entropy of the policy, through softmax
+	if s.Policy >= 0 {
+		loss -= math.Log(math.Max(a.policy[s.Policy], 1e-12))
+		for k, p := range a.policy {
+			dLogit := p
+			if k == s.Policy {
+				dLogit -= 1
+			}
+			for i, h := range a.hidden {
+				dHidden[i] += dLogit * n.WP[k][i]
+				n.WP[k][i] -= rate * dLogit * h
+			}
+			n.BP[k] -= rate * dLogit
+		}
+	}
+
+	// Back through the ReLU to the first layer
+	for i, h := range a.hidden {
+		if h <= 0 {
+			continue
+		}
+		n.B1[i] -= rate * dHidden[i]
+		for j, x := range s.Input {
+			if x != 0 {
+				n.W1[i][j] -= rate * dHidden[i] * x
+			}
+		}
+	}
+	return loss
+}
@@ -0,0 +1,157 @@
+package santorini
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/* Positions and turns can be written as plain text so they can be stored in files
+ * and sent to other programs.
+ *
+ * A tile is named by its column letter and row number, "a1" is x=0,y=0 and "c2" is x=2,y=1.
+ *
+ * A position has three space separated fields:
+ *   1. The height of every tile, one digit per tile, with rows separated by "/"
+ *   2. Every worker as team.worker:tile, separated by ","
+ *   3. The team that moves next
+ * The default position is "00000/00000/00000/00000/00000 1.1:c2,1.2:c4,2.1:b3,2.2:d3 1"
+ *
+ * A turn is written as team.worker:moveTo build, e.g. "1.1:c3d4"
+ */
+
+// SquareName returns the name of the tile at x, y
+func SquareName(x, y int) string {
+	return fmt.Sprintf("%c%d", 'a'+x, y+1)
+}
+
+// ParseSquare returns the position of a named tile
+func ParseSquare(name string) (x, y int, err error) {
+	if len(name) < 2 || name[0] < 'a' || name[0] > 'z' {
+		return 0, 0, fmt.Errorf("invalid tile %q", name)
+	}
+	row, err := strconv.Atoi(name[1:])
+	if err != nil || row < 1 {
+		return 0, 0, fmt.Errorf("invalid tile %q", name)
+	}
+	return int(name[0] - 'a'), row - 1, nil
+}
+
+// Notation returns the position of the board, with team to move next
+func (board Board) Notation(team int) string {
+	rows := make([]string, board.Size)
+	workers := make([]string, 0, 4)
+	for y := 0; y < board.Size; y++ {
+		row := make([]byte, board.Size)
+		for x := 0; x < board.Size; x++ {
+			tile := board.GetTile(x, y)
+			row[x] = byte('0' + tile.height)
+			if tile.IsOccupied() {
+				workers = append(workers, fmt.Sprintf("%d.%d:%s", tile.team, tile.worker, SquareName(x, y)))
+			}
+		}
+		rows[y] = string(row)
+	}
+	sort.Strings(workers)
+	return fmt.Sprintf("%s %s %d", strings.Join(rows, "/"), strings.Join(workers, ","), team)
+}
+
+// ParseNotation builds the board described by a position and returns it with the team to move next
+func ParseNotation(notation string) (*Board, int, error) {
+	fields := strings.Fields(notation)
+	if len(fields) != 3 {
+		return nil, 0, fmt.Errorf("position needs 3 fields, got %d", len(fields))
+	}
+
+	rows := strings.Split(fields[0], "/")
+	board := NewBoard(WithSize(len(rows)))
+	for y, row := range rows {
+		if len(row) != board.Size {
+			return nil, 0, fmt.Errorf("row %d has %d tiles, expected %d", y+1, len(row), board.Size)
+		}
+		for x := range row {
+			if row[x] < '0' || row[x] > '4' {
+				return nil, 0, fmt.Errorf("invalid height %q", row[x])
+			}
+			tile := board.GetTile(x, y)
+			tile.height = int(row[x] - '0')
+			board.setTile(tile)
+		}
+	}
+
+	for _, w := range strings.Split(fields[1], ",") {
+		team, worker, square, err := parseWorker(w)
+		if err != nil {
+			return nil, 0, err
+		}
+		x, y, err := ParseSquare(square)
+		if err != nil {
+			return nil, 0, err
+		}
+		if x >= board.Size || y >= board.Size {
+			return nil, 0, fmt.Errorf("tile %s is not on the board", square)
+		}
+		board.PlaceWorker(team, worker, x, y)
+	}
+
+	team, err := strconv.Atoi(fields[2])
+	if err != nil || !board.Teams[team] {
+		return nil, 0, fmt.Errorf("invalid team to move %q", fields[2])
+	}
+	return board, team, nil
+}
+
+// Notation returns the turn as text, e.g. "1.1:c3d4"
+func (t Turn) Notation() string {
+	return fmt.Sprintf("%d.%d:%s%s", t.Team, t.Worker, SquareName(t.MoveTo.x, t.MoveTo.y), SquareName(t.Build.x, t.Build.y))
+}
+
+// ParseTurn reads a turn written in notation. The turn is not checked for legality
+func (board Board) ParseTurn(notation string) (Turn, error) {
+	team, worker, squares, err := parseWorker(notation)
+	if err != nil {
+		return Turn{}, err
+	}
+	if len(squares) < 4 {
+		return Turn{}, fmt.Errorf("turn %q needs a move and a build", notation)
+	}
+	// Split the squares before the second letter
+	split := strings.IndexFunc(squares[1:], func(r rune) bool { return r >= 'a' && r <= 'z' }) + 1
+	if split == 0 {
+		return Turn{}, fmt.Errorf("turn %q needs a move and a build", notation)
+	}
+	mx, my, err := ParseSquare(squares[:split])
+	if err != nil {
+		return Turn{}, err
+	}
+	bx, by, err := ParseSquare(squares[split:])
+	if err != nil {
+		return Turn{}, err
+	}
+	if mx >= board.Size || my >= board.Size || bx >= board.Size || by >= board.Size {
+		return Turn{}, fmt.Errorf("turn %q is not on the board", notation)
+	}
+	return Turn{
+		Team:   team,
+		Worker: worker,
+		MoveTo: board.GetTile(mx, my),
+		Build:  board.GetTile(bx, by),
+	}, nil
+}
+
+// parseWorker splits "team.worker:rest"
+func parseWorker(s string) (team, worker int, rest string, err error) {
+	parts := strings.SplitN(s, ":", 2)
+	ids := strings.SplitN(parts[0], ".", 2)
+	if len(parts) != 2 || len(ids) != 2 {
+		return 0, 0, "", fmt.Errorf("invalid worker %q", s)
+	}
+	if team, err = strconv.Atoi(ids[0]); err != nil || team < 1 {
+		return 0, 0, "", fmt.Errorf("invalid team in %q", s)
+	}
+	if worker, err = strconv.Atoi(ids[1]); err != nil || worker < 1 {
+		return 0, 0, "", fmt.Errorf("invalid worker in %q", s)
+	}
+	return team, worker, parts[1], nil
+}
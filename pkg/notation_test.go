@@ -0,0 +1,50 @@
+package santorini
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotation(t *testing.T) {
+	board := DefaultPosition(2)
+	assert.Equal(t, "00000/00000/00000/00000/00000 1.1:c2,1.2:c4,2.1:b3,2.2:d3 1", board.Notation(1))
+
+	board.PlayTurn(Turn{Team: 1, Worker: 1, MoveTo: Tile{x: 2, y: 0}, Build: Tile{x: 3, y: 0}})
+	notation := board.Notation(2)
+	assert.Equal(t, "00010/00000/00000/00000/00000 1.1:c1,1.2:c4,2.1:b3,2.2:d3 2", notation)
+
+	parsed, team, err := ParseNotation(notation)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, team)
+	assert.Equal(t, board.Tiles, parsed.Tiles)
+	assert.Equal(t, notation, parsed.Notation(2))
+}
+
+func TestParseNotationErrors(t *testing.T) {
+	for _, notation := range []string{
+		"",
+		"0000/00000/00000/00000/00000 1.1:c2 1",
+		"00000/00000/00000/00000/00005 1.1:c2 1",
+		"00000/00000/00000/00000/00000 1.1:f2 1",
+		"00000/00000/00000/00000/00000 1.1:c2 2",
+	} {
+		_, _, err := ParseNotation(notation)
+		assert.Error(t, err, notation)
+	}
+}
+
+func TestTurnNotation(t *testing.T) {
+	board := DefaultPosition(2)
+	turn := Turn{Team: 2, Worker: 1, MoveTo: board.GetTile(0, 2), Build: board.GetTile(0, 3)}
+	assert.Equal(t, "2.1:a3a4", turn.Notation())
+
+	parsed, err := board.ParseTurn("2.1:a3a4")
+	assert.NoError(t, err)
+	assert.Equal(t, turn, parsed)
+
+	for _, notation := range []string{"2.1:a3", "2:a3a4", "2.1:a3f1", "x.1:a3a4"} {
+		_, err := board.ParseTurn(notation)
+		assert.Error(t, err, notation)
+	}
+}
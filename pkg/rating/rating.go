@@ -0,0 +1,218 @@
+/* Package rating keeps Elo and Glicko-2 ratings of bots across runs.
+ *
+ * Ratings are kept in a ledger keyed by the bot's spec, so every configuration of a bot has its
+ * own rating. Every game is its own Glicko-2 rating period, so the ratings move after each game
+ * like Elo does, while the rating deviation says how sure the ledger is of each rating.
+ */
+package rating
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"sort"
+)
+
+const (
+	initialRating     = 1500
+	initialDeviation  = 350
+	initialVolatility = 0.06
+	// eloK is how far a single Elo game can move a rating
+	eloK = 32
+	// tau limits how quickly the volatility changes, Glickman suggests 0.3 to 1.2
+	tau = 0.5
+	// glickoScale converts ratings between the Elo like scale and the Glicko-2 scale
+	glickoScale = 173.7178
+)
+
+// Rating is a bot's standing in the ledger
+type Rating struct {
+	Elo        float64 `json:"elo"`
+	Glicko     float64 `json:"glicko"`     // Glicko-2 rating, on the same scale as Elo
+	Deviation  float64 `json:"deviation"`  // Glicko-2 rating deviation
+	Volatility float64 `json:"volatility"` // Glicko-2 volatility, how erratic the bot's results are
+	Wins       int     `json:"wins"`
+	Losses     int     `json:"losses"`
+	Draws      int     `json:"draws"`
+}
+
+func newRating() *Rating {
+	return &Rating{
+		Elo:        initialRating,
+		Glicko:     initialRating,
+		Deviation:  initialDeviation,
+		Volatility: initialVolatility,
+	}
+}
+
+func (r Rating) Games() int {
+	return r.Wins + r.Losses + r.Draws
+}
+
+// Interval returns the range the Glicko-2 rating is in with 95% confidence
+func (r Rating) Interval() (float64, float64) {
+	return r.Glicko - 1.96*r.Deviation, r.Glicko + 1.96*r.Deviation
+}
+
+// Ledger holds the rating of every bot
+type Ledger struct {
+	Ratings map[string]*Rating `json:"ratings"`
+}
+
+func NewLedger() *Ledger {
+	return &Ledger{Ratings: make(map[string]*Rating)}
+}
+
+// Load reads a ledger from a JSON file. A missing file is an empty ledger
+func Load(path string) (*Ledger, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewLedger(), nil
+	} else if err != nil {
+		return nil, err
+	}
+	l := NewLedger()
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, err
+	}
+	if l.Ratings == nil {
+		l.Ratings = make(map[string]*Rating)
+	}
+	return l, nil
+}
+
+// Save the ledger to a JSON file
+func (l *Ledger) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Get returns the bot's rating, starting a new one if the bot has not played yet
+func (l *Ledger) Get(name string) *Rating {
+	r, ok := l.Ratings[name]
+	if !ok {
+		r = newRating()
+		l.Ratings[name] = r
+	}
+	return r
+}
+
+// Record a game between two bots. The score is a's result: 1 for a win, 0.5 for a draw and 0 for a loss
+func (l *Ledger) Record(a, b string, score float64) {
+	if a == b {
+		// A bot playing itself tells us nothing
+		return
+	}
+	ra, rb := l.Get(a), l.Get(b)
+	before := *ra
+
+	ea := 1 / (1 + math.Pow(10, (rb.Elo-ra.Elo)/400))
+	ra.Elo += eloK * (score - ea)
+	rb.Elo += eloK * (ea - score)
+
+	*ra = glicko2(*ra, []result{{opponent: *rb, score: score}})
+	*rb = glicko2(*rb, []result{{opponent: before, score: 1 - score}})
+
+	switch score {
+	case 1:
+		ra.Wins++
+		rb.Losses++
+	case 0:
+		ra.Losses++
+		rb.Wins++
+	default:
+		ra.Draws++
+		rb.Draws++
+	}
+}
+
+// Standing is a bot's place on the leaderboard
+type Standing struct {
+	Name string
+	Rating
+}
+
+// Leaderboard returns every bot from the highest to the lowest Glicko-2 rating
+func (l *Ledger) Leaderboard() []Standing {
+	standings := make([]Standing, 0, len(l.Ratings))
+	for name, r := range l.Ratings {
+		standings = append(standings, Standing{Name: name, Rating: *r})
+	}
+	sort.Slice(standings, func(i, j int) bool {
+		if standings[i].Glicko != standings[j].Glicko {
+			return standings[i].Glicko > standings[j].Glicko
+		}
+		return standings[i].Name < standings[j].Name
+	})
+	return standings
+}
+
+// result is one game of a Glicko-2 rating period
+type result struct {
+	opponent Rating
+	score    float64
+}
+
+// glicko2 returns the rating after a rating period, following Glickman's "Example of the Glicko-2 system"
+func glicko2(r Rating, results []result) Rating {
+	mu := (r.Glicko - initialRating) / glickoScale
+	phi := r.Deviation / glickoScale
+	sigma := r.Volatility
+
+	g := func(phi float64) float64 {
+		return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+	}
+	// Estimated variance of the rating from the games, and the estimated improvement
+	var v, delta float64
+	for _, res := range results {
+		muj := (res.opponent.Glicko - initialRating) / glickoScale
+		gj := g(res.opponent.Deviation / glickoScale)
+		e := 1 / (1 + math.Exp(-gj*(mu-muj)))
+		v += gj * gj * e * (1 - e)
+		delta += gj * (res.score - e)
+	}
+	v = 1 / v
+	delta *= v
+
+	// Find the new volatility with the Illinois algorithm
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/(tau*tau)
+	}
+	lo, hi := a, 0.0
+	if delta*delta > phi*phi+v {
+		hi = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		hi = a - k*tau
+	}
+	flo, fhi := f(lo), f(hi)
+	for math.Abs(hi-lo) > 1e-6 {
+		c := lo + (lo-hi)*flo/(fhi-flo)
+		fc := f(c)
+		if fc*fhi <= 0 {
+			lo, flo = hi, fhi
+		} else {
+			flo /= 2
+		}
+		hi, fhi = c, fc
+	}
+	sigma = math.Exp(lo / 2)
+
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	mu += phi * phi * delta / v
+
+	r.Glicko = mu*glickoScale + initialRating
+	r.Deviation = phi * glickoScale
+	r.Volatility = sigma
+	return r
+}
@@ -0,0 +1,55 @@
+package rating
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlicko2Example(t *testing.T) {
+	// The worked example from Glickman's "Example of the Glicko-2 system"
+	player := Rating{Glicko: 1500, Deviation: 200, Volatility: 0.06}
+	after := glicko2(player, []result{
+		{opponent: Rating{Glicko: 1400, Deviation: 30}, score: 1},
+		{opponent: Rating{Glicko: 1550, Deviation: 100}, score: 0},
+		{opponent: Rating{Glicko: 1700, Deviation: 300}, score: 0},
+	})
+	assert.InDelta(t, 1464.06, after.Glicko, 0.01)
+	assert.InDelta(t, 151.52, after.Deviation, 0.01)
+	assert.InDelta(t, 0.05999, after.Volatility, 0.00001)
+}
+
+func TestRecord(t *testing.T) {
+	l := NewLedger()
+	l.Record("BasicBot", "RandomBot", 1)
+	basic, random := l.Get("BasicBot"), l.Get("RandomBot")
+	assert.Equal(t, 1516.0, basic.Elo)
+	assert.Equal(t, 1484.0, random.Elo)
+	assert.Greater(t, basic.Glicko, 1500.0)
+	assert.Less(t, random.Glicko, 1500.0)
+	assert.Less(t, basic.Deviation, 350.0)
+	assert.Equal(t, 1, basic.Wins)
+	assert.Equal(t, 1, random.Losses)
+
+	l.Record("BasicBot", "BasicBot", 1)
+	assert.Equal(t, 1, basic.Games())
+
+	board := l.Leaderboard()
+	assert.Equal(t, "BasicBot", board[0].Name)
+	lo, hi := board[0].Interval()
+	assert.True(t, lo < board[0].Glicko && board[0].Glicko < hi)
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratings.json")
+	l, err := Load(path)
+	assert.NoError(t, err)
+	assert.Empty(t, l.Ratings)
+
+	l.Record("KyleBot", "RandomBot", 0.5)
+	assert.NoError(t, l.Save(path))
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, l, loaded)
+}
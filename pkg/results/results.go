@@ -0,0 +1,242 @@
+/* Package results records finished games for analysis.
+ *
+ * Simulations write a record of every game to a JSON Lines or CSV file, which can be read back to
+ * build reports or to diff the results of different versions of the bots.
+ */
+package results
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	santorini "santorini/pkg"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is everything about a finished game that is exported for analysis
+type Record struct {
+	Number     int       `json:"number"`
+	Seed       int64     `json:"seed"`
+	Bots       []string  `json:"bots"`  // The spec of the bot playing each team, the first went first
+	First      string    `json:"first"` // The spec of the bot that went first
+	Victor     int       `json:"victor"`
+	Winner     string    `json:"winner"`
+	Places     []int     `json:"places"` // The place each team finished in, 1 for the victor
+	Reason     string    `json:"reason"`
+	Turns      int       `json:"turns"`
+	DurationMS float64   `json:"duration_ms"`
+	TurnTimeMS []float64 `json:"turn_time_ms"` // How long each turn in Moves took to choose
+	Moves      []string  `json:"moves"`
+	Opening    string    `json:"opening"`         // The position the game started from
+	Error      string    `json:"error,omitempty"` // Why a bot forfeited the game
+}
+
+// NewRecord describes the game, bots are the specs of the bots playing each team
+func NewRecord(sim *santorini.Simulation, bots []string) Record {
+	r := Record{
+		Number:     sim.Number,
+		Seed:       sim.Seed,
+		Bots:       bots,
+		Victor:     sim.Board.Victor,
+		Places:     sim.Places(),
+		Reason:     sim.Reason,
+		Turns:      len(sim.Board.Moves),
+		DurationMS: milliseconds(sim.Duration),
+		TurnTimeMS: make([]float64, len(sim.TurnTimes)),
+		Moves:      make([]string, len(sim.Board.Moves)),
+		Opening:    sim.Start.Notation(1),
+	}
+	r.First = r.Bots[0]
+	if r.Victor > 0 {
+		r.Winner = r.Bots[r.Victor-1]
+	}
+	if len(sim.Crashes) > 0 {
+		crash := sim.Crashes[0]
+		r.Error = fmt.Sprintf("team %d (%s): %s", crash.Team, crash.Bot, crash.Err)
+	}
+	for i, d := range sim.TurnTimes {
+		r.TurnTimeMS[i] = milliseconds(d)
+	}
+	for i, turn := range sim.Board.Moves {
+		r.Moves[i] = turn.Notation()
+	}
+	return r
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Writer writes a record of every game to a JSON Lines or CSV file
+type Writer struct {
+	file *os.File
+	json *json.Encoder
+	csv  *csv.Writer
+}
+
+// CSVHeader names the CSV columns. Bots are separated by semicolons, since specs may have spaces,
+// and the other lists by spaces
+var CSVHeader = []string{"number", "seed", "bots", "first", "victor", "winner", "places", "reason", "turns", "duration_ms", "turn_time_ms", "moves", "opening", "error"}
+
+// NewWriter creates the file, writing CSV when its name ends in .csv and JSON Lines otherwise
+func NewWriter(path string) (*Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{file: file}
+	if strings.HasSuffix(strings.ToLower(path), ".csv") {
+		w.csv = csv.NewWriter(file)
+		if err := w.csv.Write(CSVHeader); err != nil {
+			file.Close()
+			return nil, err
+		}
+	} else {
+		w.json = json.NewEncoder(file)
+	}
+	return w, nil
+}
+
+func (w *Writer) Write(r Record) error {
+	if w.json != nil {
+		return w.json.Encode(r)
+	}
+	places := make([]string, len(r.Places))
+	for i, place := range r.Places {
+		places[i] = strconv.Itoa(place)
+	}
+	times := make([]string, len(r.TurnTimeMS))
+	for i, ms := range r.TurnTimeMS {
+		times[i] = strconv.FormatFloat(ms, 'f', 3, 64)
+	}
+	return w.csv.Write([]string{
+		strconv.Itoa(r.Number),
+		strconv.FormatInt(r.Seed, 10),
+		strings.Join(r.Bots, ";"),
+		r.First,
+		strconv.Itoa(r.Victor),
+		r.Winner,
+		strings.Join(places, " "),
+		r.Reason,
+		strconv.Itoa(r.Turns),
+		strconv.FormatFloat(r.DurationMS, 'f', 3, 64),
+		strings.Join(times, " "),
+		strings.Join(r.Moves, " "),
+		r.Opening,
+		r.Error,
+	})
+}
+
+func (w *Writer) Close() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			w.file.Close()
+			return err
+		}
+	}
+	return w.file.Close()
+}
+
+// Read returns the records in a file written by Writer
+func Read(path string) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []Record
+	if !strings.HasSuffix(strings.ToLower(path), ".csv") {
+		decoder := json.NewDecoder(file)
+		for decoder.More() {
+			var r Record
+			if err := decoder.Decode(&r); err != nil {
+				return nil, fmt.Errorf("%s: record %d: %w", path, len(records)+1, err)
+			}
+			if len(r.Places) != len(r.Bots) {
+				return nil, fmt.Errorf("%s: record %d: places for %d teams, expected %d", path, len(records)+1, len(r.Places), len(r.Bots))
+			}
+			records = append(records, r)
+		}
+		return records, nil
+	}
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[name] = i
+	}
+	for _, name := range CSVHeader {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%s has no %s column", path, name)
+		}
+	}
+	for n, row := range rows[1:] {
+		r, err := parseRow(func(name string) string {
+			return row[columns[name]]
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: row %d: %w", path, n+2, err)
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// parseRow reads a record from the columns of a CSV row
+func parseRow(column func(name string) string) (Record, error) {
+	r := Record{
+		Bots:    strings.Split(column("bots"), ";"),
+		First:   column("first"),
+		Winner:  column("winner"),
+		Reason:  column("reason"),
+		Moves:   strings.Fields(column("moves")),
+		Opening: column("opening"),
+		Error:   column("error"),
+	}
+	var err error
+	if r.Number, err = strconv.Atoi(column("number")); err != nil {
+		return r, err
+	}
+	if r.Seed, err = strconv.ParseInt(column("seed"), 10, 64); err != nil {
+		return r, err
+	}
+	if r.Victor, err = strconv.Atoi(column("victor")); err != nil {
+		return r, err
+	}
+	if r.Turns, err = strconv.Atoi(column("turns")); err != nil {
+		return r, err
+	}
+	if r.DurationMS, err = strconv.ParseFloat(column("duration_ms"), 64); err != nil {
+		return r, err
+	}
+	for _, field := range strings.Fields(column("places")) {
+		place, err := strconv.Atoi(field)
+		if err != nil {
+			return r, err
+		}
+		r.Places = append(r.Places, place)
+	}
+	if len(r.Places) != len(r.Bots) {
+		return r, fmt.Errorf("places for %d teams, expected %d", len(r.Places), len(r.Bots))
+	}
+	r.TurnTimeMS = make([]float64, 0, r.Turns)
+	for _, field := range strings.Fields(column("turn_time_ms")) {
+		ms, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return r, err
+		}
+		r.TurnTimeMS = append(r.TurnTimeMS, ms)
+	}
+	return r, nil
+}
@@ -0,0 +1,61 @@
+package results
+
+import (
+	"os"
+	"path/filepath"
+	"santorini/bots"
+	santorini "santorini/pkg"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAndRead(t *testing.T) {
+	sim := santorini.NewSeededSimulator(1, 1, logrus.StandardLogger(), bots.NewBasicBot, bots.NewKyleBot)
+	sim.Run()
+	r := NewRecord(sim, []string{"BasicBot", "exec:engine --fast"})
+	assert.Equal(t, "BasicBot", r.First)
+	assert.Equal(t, r.Bots[sim.Board.Victor-1], r.Winner)
+	assert.Equal(t, 1, r.Places[sim.Board.Victor-1])
+	assert.Contains(t, []string{santorini.ReasonClimbed, santorini.ReasonTrapped}, r.Reason)
+	assert.Len(t, r.Moves, r.Turns)
+	assert.Len(t, r.TurnTimeMS, r.Turns)
+	assert.Equal(t, sim.Start.Notation(1), r.Opening)
+
+	dir := t.TempDir()
+	for _, name := range []string{"results.jsonl", "results.csv"} {
+		path := filepath.Join(dir, name)
+		w, err := NewWriter(path)
+		assert.NoError(t, err)
+		assert.NoError(t, w.Write(r))
+		assert.NoError(t, w.Write(r))
+		assert.NoError(t, w.Close())
+
+		read, err := Read(path)
+		assert.NoError(t, err, name)
+		if assert.Len(t, read, 2, name) {
+			if name == "results.csv" {
+				// CSV keeps turn times to the microsecond
+				assert.InDeltaSlice(t, r.TurnTimeMS, read[1].TurnTimeMS, 0.001)
+				assert.InDelta(t, r.DurationMS, read[1].DurationMS, 0.001)
+				read[1].TurnTimeMS, read[1].DurationMS = r.TurnTimeMS, r.DurationMS
+			}
+			assert.Equal(t, r, read[1], name)
+		}
+	}
+}
+
+func TestReadWithoutPlaces(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"results.jsonl": `{"number":0,"bots":["BasicBot","KyleBot"],"victor":1}` + "\n",
+		"results.csv":   "number,seed,bots,first,victor,winner,reason,turns,duration_ms,moves,opening,error,turn_time_ms\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		_, err := Read(path)
+		assert.Error(t, err, name)
+	}
+}
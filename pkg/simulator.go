@@ -1,9 +1,12 @@
 package santorini
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
+	"io"
+	"math/rand"
+	"runtime"
+	"runtime/debug"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -21,17 +24,96 @@ type TurnSelector interface {
 	IsDeterministic() bool
 }
 
+// Seedable bots make their random choices from a seed, so the simulator can replay a game exactly
+type Seedable interface {
+	Seed(seed int64)
+}
+
+// Ponderer bots think while another team chooses its turn. Ponder is called once that team's turn
+// starts, with the live board, which it must copy before returning. The bot stops pondering by
+// itself when it is asked for a turn, or when StopPondering is called
+type Ponderer interface {
+	Ponder(board *Board, team int)
+	StopPondering()
+}
+
+// Interrupter bots can be told to stop choosing a turn early, from another goroutine. SelectTurn then
+// returns the best turn it has found so far
+type Interrupter interface {
+	Interrupt()
+}
+
+// Reasons a game can end
+const (
+	ReasonClimbed    = "climbed"    // The victor moved up to level 3
+	ReasonTrapped    = "trapped"    // The other team had no moves left
+	ReasonEliminated = "eliminated" // Every other team ran out of moves in a game of more than two teams
+	ReasonForfeit    = "forfeit"    // The other team's bot crashed, played an illegal turn or ran out of time
+)
+
+// Allocs is the memory allocated on the heap
+type Allocs struct {
+	Bytes   uint64
+	Objects uint64
+}
+
+// Crash records a bot that forfeited a game
+type Crash struct {
+	Team     int
+	Bot      string
+	Err      error
+	Stack    string // Where the bot panicked, empty for illegal turns and timeouts
+	Position string // Notation of the position the bot was asked to play, to reproduce the crash
+}
+
 type Simulation struct {
 	Number int
+	Seed   int64  // Every random choice in the game is derived from the seed
+	Start  *Board // The position the game started from
 	Board  *Board
 	Teams  []TurnSelector
+	// Ask bots to explain every turn, for bots that implement Explainer
+	Explain bool
+	// The explanation of every turn in Board.Moves, nil where the bot gave none
+	Explanations []*Explanation
+	// Why the game ended, one of the Reason constants
+	Reason string
+	// How long the game took, and how long each bot took to choose every turn in Board.Moves
+	Duration  time.Duration
+	TurnTimes []time.Duration
+	// How long a bot may take to choose a turn before it forfeits, no limit when 0
+	TurnTimeout time.Duration
+	// Every bot that forfeited the game
+	Crashes []Crash
+	// Teams in the order they were knocked out by running out of moves or forfeiting, see Places
+	Eliminated []int
+	// Count the memory allocated choosing every turn in Board.Moves. Counting stops the world and
+	// includes every goroutine's allocations, so it is only accurate when one game runs at a time
+	MeasureAllocs bool
+	TurnAllocs    []Allocs
+	// The team whose turns are chosen through profiledSelectTurn, so profiles can be focused on its bot
+	ProfileTeam int
 
-	logger *logrus.Logger
-	round  int
+	logger     *logrus.Logger
+	round      int
+	eliminated map[int]bool          // Teams that ran out of moves in a game of more than two teams
+	boards     []*Board              // The board each team's bot plays on, kept in step with Board
+	selecting  map[int]chan struct{} // Closed once the bot of a team that ran out of time returns its turn
 }
 
+// NewSimulator creates a game with a seed taken from the clock
 func NewSimulator(number int, logger *logrus.Logger, bots ...BotInitializer) *Simulation {
-	b := DefaultPosition(len(bots))
+	return NewSeededSimulator(number, time.Now().UnixNano(), logger, bots...)
+}
+
+// NewSeededSimulator creates a game that plays out the same way every time it is given the same seed
+func NewSeededSimulator(number int, seed int64, logger *logrus.Logger, bots ...BotInitializer) *Simulation {
+	return NewPositionSimulator(number, seed, DefaultPosition(len(bots)), logger, bots...)
+}
+
+// NewPositionSimulator creates a seeded game that starts from a copy of the position, with team 1 to move
+func NewPositionSimulator(number int, seed int64, start *Board, logger *logrus.Logger, bots ...BotInitializer) *Simulation {
+	b := start.Clone()
 	lgr := logger
 	// Unless we are debugging, hide all bot logs except for fatal ones
 	if logger.Level != logrus.DebugLevel {
@@ -44,74 +126,290 @@ func NewSimulator(number int, logger *logrus.Logger, bots ...BotInitializer) *Si
 		}
 	}
 
-	var teams []TurnSelector
+	// Every bot chooses its turns on its own copy of the board, so a bot that runs out of time can be
+	// left to finish in the background without racing the rest of the game
+	boards := make([]*Board, len(bots))
+	teams := make([]TurnSelector, len(bots))
+	for i, bot := range bots {
+		boards[i] = b.Clone()
+		teams[i] = bot(i+1, boards[i], lgr)
+	}
 
-	switch len(bots) {
-	case 3:
-		teams = []TurnSelector{
-			bots[0](1, b, lgr),
-			bots[1](2, b, lgr),
-			bots[2](3, b, lgr),
-		}
-	case 2:
-		teams = []TurnSelector{
-			bots[0](1, b, lgr),
-			bots[1](2, b, lgr),
-		}
-	case 1:
-		teams = []TurnSelector{
-			bots[0](1, b, lgr),
+	// Every bot gets its own seed
+	rng := rand.New(rand.NewSource(seed))
+	for _, team := range teams {
+		if bot, ok := team.(Seedable); ok {
+			bot.Seed(rng.Int63())
 		}
 	}
 	return &Simulation{
 		Number: number,
+		Seed:   seed,
+		Start:  b.Clone(),
 		Board:  b,
 		Teams:  teams,
 		logger: logger,
+		boards: boards,
 	}
 }
 
 // doRound returns true when a team wins, false otherwise
-func (sim *Simulation) doRound() bool {
+func (sim *Simulation) doRound() (over bool) {
 	sim.round += 1
 	// Loop vars here so they can be used by panic
 	var bot TurnSelector
 	var i int
+	var position string
 	defer func() {
+		// Explaining or playing the turn went wrong
 		if err := recover(); err != nil {
-			b, _ := json.Marshal(sim.Board)
-			fmt.Fprintln(os.Stderr, bot.Name(), "caused a panic:", err)
-			fmt.Println(string(b))
-			os.Exit(1)
+			over = sim.forfeit(Crash{Team: i + 1, Bot: bot.Name(), Err: fmt.Errorf("%v", err), Stack: string(debug.Stack()), Position: position})
 		}
 	}()
 	for i, bot = range sim.Teams {
-		turn := bot.SelectTurn()
+		if sim.eliminated[i+1] {
+			continue
+		}
+		position = sim.Board.Notation(i + 1)
+		start := time.Now()
+		turn, allocs, crash := sim.selectTurn(bot, i+1)
+		elapsed := time.Since(start)
+		if crash != nil {
+			crash.Team, crash.Bot, crash.Position = i+1, bot.Name(), position
+			if sim.forfeit(*crash) {
+				return true
+			}
+			continue
+		}
 		if turn == nil {
 			sim.logger.Debugf("Team %d (%s) has no moves", i+1, bot.Name())
-			// This function may break if there are three turns and two run out of moves
 			if len(sim.Teams) == 2 {
-				sim.Board.Victor = sim.Board.lastTeam
+				// The other team wins, even if it has not moved yet
+				sim.Board.Victor = 2 - i
+				sim.Reason = ReasonTrapped
+				sim.Eliminated = append(sim.Eliminated, i+1)
+				return true
+			}
+			if sim.eliminate(i + 1) {
+				return true
+			}
+			continue
+		}
+		if !sim.isLegal(i+1, *turn) {
+			if sim.forfeit(Crash{Team: i + 1, Bot: bot.Name(), Err: fmt.Errorf("illegal turn %s", turn.Notation()), Position: position}) {
 				return true
 			}
 			continue
 		}
 
-		if sim.Board.PlayTurn(*turn) {
+		if sim.Explain {
+			var explanation *Explanation
+			if explainer, ok := bot.(Explainer); ok {
+				explanation = explainer.Explain(3)
+			}
+			sim.Explanations = append(sim.Explanations, explanation)
+		}
+		moves := len(sim.Board.Moves)
+		gameover := sim.Board.PlayTurn(*turn)
+		if len(sim.Board.Moves) > moves {
+			sim.TurnTimes = append(sim.TurnTimes, elapsed)
+			if sim.MeasureAllocs {
+				sim.TurnAllocs = append(sim.TurnAllocs, allocs)
+			}
+		}
+		if gameover {
+			// The board also ends the game when a team it found without moves is the only one left
+			sim.Reason = ReasonTrapped
+			if len(sim.Board.Moves) > moves && turn.IsVictory() {
+				sim.Reason = ReasonClimbed
+			}
+			return true
+		}
+		sim.syncBoards()
+	}
+
+	return false
+}
+
+// syncBoards copies the game to the boards of the teams still playing. The boards of teams that are
+// out are left alone, their bots may still be choosing a turn after running out of time
+func (sim *Simulation) syncBoards() {
+	for i, board := range sim.boards {
+		if !sim.eliminated[i+1] {
+			*board = *sim.Board.Clone()
+		}
+	}
+}
+
+// selectTurn asks the team's bot for its turn, returning a crash instead when the bot panics or runs
+// out of time. The memory the bot allocated is counted when MeasureAllocs is set
+func (sim *Simulation) selectTurn(bot TurnSelector, team int) (*Turn, Allocs, *Crash) {
+	type result struct {
+		turn   *Turn
+		allocs Allocs
+		crash  *Crash
+	}
+	selected := make(chan result, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if err := recover(); err != nil {
+				selected <- result{crash: &Crash{Err: fmt.Errorf("%v", err), Stack: string(debug.Stack())}}
+			}
+		}()
+		var before, after runtime.MemStats
+		if sim.MeasureAllocs {
+			runtime.ReadMemStats(&before)
+		}
+		var turn *Turn
+		if team == sim.ProfileTeam {
+			turn = profiledSelectTurn(bot)
+		} else {
+			turn = bot.SelectTurn()
+		}
+		var allocs Allocs
+		if sim.MeasureAllocs {
+			runtime.ReadMemStats(&after)
+			allocs = Allocs{Bytes: after.TotalAlloc - before.TotalAlloc, Objects: after.Mallocs - before.Mallocs}
+		}
+		selected <- result{turn: turn, allocs: allocs}
+	}()
+
+	var timeout <-chan time.Time
+	if sim.TurnTimeout > 0 {
+		timer := time.NewTimer(sim.TurnTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case r := <-selected:
+		return r.turn, r.allocs, r.crash
+	case <-timeout:
+		// The bot is left to finish in the background, the game is over for it
+		if i, ok := bot.(Interrupter); ok {
+			i.Interrupt()
+		}
+		if sim.selecting == nil {
+			sim.selecting = make(map[int]chan struct{})
+		}
+		sim.selecting[team] = done
+		return nil, Allocs{}, &Crash{Err: fmt.Errorf("no turn after %s", sim.TurnTimeout)}
+	}
+}
+
+// profiledSelectTurn only calls SelectTurn, so the profiled bot's samples can be found in a CPU or heap
+// profile with go tool pprof -focus=profiledSelectTurn
+//
+//go:noinline
+func profiledSelectTurn(bot TurnSelector) *Turn {
+	return bot.SelectTurn()
+}
+
+// isLegal returns true if the turn is one the team can play
+func (sim *Simulation) isLegal(team int, turn Turn) bool {
+	for _, valid := range sim.Board.GetValidTurns(team) {
+		if valid.Team == turn.Team && valid.Worker == turn.Worker &&
+			valid.MoveTo.x == turn.MoveTo.x && valid.MoveTo.y == turn.MoveTo.y &&
+			valid.Build.x == turn.Build.x && valid.Build.y == turn.Build.y {
 			return true
 		}
 	}
+	return false
+}
 
+// forfeit records the crash and takes the bot's team out of the game, returning true if the game is over
+func (sim *Simulation) forfeit(crash Crash) bool {
+	sim.logger.Debugf("Team %d (%s) forfeits: %s", crash.Team, crash.Bot, crash.Err)
+	sim.Crashes = append(sim.Crashes, crash)
+	if len(sim.Teams) == 2 {
+		sim.Board.Victor = 3 - crash.Team
+		sim.Board.IsOver = true
+		sim.Reason = ReasonForfeit
+		sim.Eliminated = append(sim.Eliminated, crash.Team)
+		return true
+	}
+	if sim.eliminate(crash.Team) {
+		sim.Reason = ReasonForfeit
+		return true
+	}
 	return false
 }
 
+// eliminate takes a team out of the game, and returns true if only one team is left to win it
+func (sim *Simulation) eliminate(team int) bool {
+	if sim.eliminated == nil {
+		sim.eliminated = make(map[int]bool)
+	}
+	sim.eliminated[team] = true
+	sim.Eliminated = append(sim.Eliminated, team)
+	sim.Board.Eliminate(team)
+	if len(sim.eliminated) < len(sim.Teams)-1 {
+		sim.syncBoards()
+		return false
+	}
+	for i := range sim.Teams {
+		if !sim.eliminated[i+1] {
+			sim.Board.Victor = i + 1
+		}
+	}
+	sim.Board.IsOver = true
+	sim.Reason = ReasonEliminated
+	return true
+}
+
 // Run a game until it's completion
 func (sim *Simulation) Run() {
+	start := time.Now()
 	for !sim.doRound() {
 		//log.Printf("Completed Round %d", sim.round)
 	}
+	sim.Duration = time.Since(start)
 
-	sim.logger.Debugf("Simulation %d Completed, Team %d (%s) won after %d rounds", sim.Number, sim.Board.Victor, sim.Teams[sim.Board.Victor-1].Name(), sim.round)
+	sim.logger.Debugf("Simulation %d (seed %d) Completed, Team %d (%s) won after %d rounds", sim.Number, sim.Seed, sim.Board.Victor, sim.Teams[sim.Board.Victor-1].Name(), sim.round)
+
+	// Shut down bots that hold on to resources, such as external engines. Bots that ran out of time
+	// are closed once they stop choosing their turn
+	for i, bot := range sim.Teams {
+		closer, ok := bot.(io.Closer)
+		if !ok {
+			continue
+		}
+		if done, ok := sim.selecting[i+1]; ok {
+			go func() {
+				<-done
+				closer.Close()
+			}()
+		} else {
+			closer.Close()
+		}
+	}
+}
+
+// Places returns the place each team finished the game in, places[team-1] is 1 for the victor. Teams
+// still playing when the game was won share the place after the victor, and eliminated teams are
+// placed after them, the last team to be knocked out first
+func (sim *Simulation) Places() []int {
+	places := make([]int, len(sim.Teams))
+	if sim.Board.Victor > 0 {
+		places[sim.Board.Victor-1] = 1
+	}
+	for _, team := range sim.Eliminated {
+		places[team-1] = -1
+	}
+	playing := 0
+	for team, place := range places {
+		if place == 0 {
+			places[team] = 2
+			playing++
+		}
+	}
+	next := 2 + playing
+	for i := len(sim.Eliminated) - 1; i >= 0; i-- {
+		places[sim.Eliminated[i]-1] = next
+		next++
+	}
+	return places
 }
 
 // Default starting position for bots
@@ -120,7 +418,17 @@ func DefaultPosition(numTeams int) *Board {
 
 	workers := make([][]int, 0, numTeams)
 
-	if numTeams == 3 {
+	if numTeams == 4 {
+		workers = append(workers,
+			[]int{1, 1, 1, 1},
+			[]int{1, 2, 3, 3},
+			[]int{2, 1, 3, 1},
+			[]int{2, 2, 1, 3},
+			[]int{3, 1, 2, 0},
+			[]int{3, 2, 2, 4},
+			[]int{4, 1, 0, 2},
+			[]int{4, 2, 4, 2})
+	} else if numTeams == 3 {
 		workers = append(workers,
 			[]int{1, 1, 0, 1},
 			[]int{1, 2, 4, 1},
@@ -146,3 +454,22 @@ func DefaultPosition(numTeams int) *Board {
 
 	return board
 }
+
+// RandomPosition places every team's two workers on random tiles, after building builds random
+// blocks no higher than level 2 so no worker can win on the first turn
+func RandomPosition(rng *rand.Rand, numTeams, builds int) *Board {
+	board := NewBoard()
+	for i := 0; i < builds; i++ {
+		tile := board.Tiles[rng.Intn(len(board.Tiles))]
+		if tile.height < 2 {
+			tile.height++
+			board.setTile(tile)
+		}
+	}
+	order := rng.Perm(len(board.Tiles))
+	for i := 0; i < 2*numTeams; i++ {
+		tile := board.Tiles[order[i]]
+		board.PlaceWorker(i/2+1, i%2+1, tile.x, tile.y)
+	}
+	return board
+}
@@ -0,0 +1,160 @@
+package santorini
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// testBot plays the first valid turn, unless it is told to misbehave
+type testBot struct {
+	team  int
+	board *Board
+	play  func(turns []Turn) *Turn
+}
+
+func (bot testBot) Name() string          { return "TestBot" }
+func (bot testBot) IsDeterministic() bool { return true }
+func (bot testBot) SelectTurn() *Turn {
+	turns := bot.board.GetValidTurns(bot.team)
+	if len(turns) == 0 {
+		return nil
+	}
+	return bot.play(turns)
+}
+
+func newTestBot(play func(turns []Turn) *Turn) BotInitializer {
+	return func(team int, board *Board, logger *logrus.Logger) TurnSelector {
+		return testBot{team: team, board: board, play: play}
+	}
+}
+
+func TestSimulationForfeits(t *testing.T) {
+	first := newTestBot(func(turns []Turn) *Turn { return &turns[0] })
+	for name, bot := range map[string]BotInitializer{
+		"panics": newTestBot(func(turns []Turn) *Turn { panic("out of ideas") }),
+		"illegal": newTestBot(func(turns []Turn) *Turn {
+			turn := turns[0]
+			turn.Worker = 3
+			return &turn
+		}),
+		"slow": newTestBot(func(turns []Turn) *Turn {
+			time.Sleep(time.Second)
+			return &turns[0]
+		}),
+	} {
+		sim := NewSeededSimulator(0, 1, logrus.StandardLogger(), first, bot)
+		sim.TurnTimeout = 50 * time.Millisecond
+		sim.Run()
+		assert.Equal(t, 1, sim.Board.Victor, name)
+		assert.Equal(t, ReasonForfeit, sim.Reason, name)
+		if assert.Len(t, sim.Crashes, 1, name) {
+			crash := sim.Crashes[0]
+			assert.Equal(t, 2, crash.Team, name)
+			assert.Equal(t, "TestBot", crash.Bot, name)
+			assert.Equal(t, name == "panics", crash.Stack != "", name)
+			// The position can be loaded to reproduce the crash
+			_, team, err := ParseNotation(crash.Position)
+			assert.NoError(t, err, name)
+			assert.Equal(t, 2, team, name)
+		}
+	}
+
+	// With three teams the game goes on without the bot that crashed
+	crashing := newTestBot(func(turns []Turn) *Turn { panic("out of ideas") })
+	sim := NewSeededSimulator(0, 1, logrus.StandardLogger(), first, crashing, first)
+	sim.Run()
+	assert.Len(t, sim.Crashes, 1)
+	assert.NotEqual(t, 2, sim.Board.Victor)
+	assert.NotEqual(t, ReasonForfeit, sim.Reason)
+}
+
+// stuckBot chooses its turn only once it is interrupted
+type stuckBot struct {
+	interrupt chan struct{}
+	finished  int32
+	closed    chan bool // Whether the bot had finished choosing when it was closed
+}
+
+func (bot *stuckBot) Name() string          { return "StuckBot" }
+func (bot *stuckBot) IsDeterministic() bool { return true }
+func (bot *stuckBot) Interrupt()            { close(bot.interrupt) }
+func (bot *stuckBot) SelectTurn() *Turn {
+	<-bot.interrupt
+	time.Sleep(10 * time.Millisecond)
+	atomic.StoreInt32(&bot.finished, 1)
+	return nil
+}
+func (bot *stuckBot) Close() error {
+	bot.closed <- atomic.LoadInt32(&bot.finished) == 1
+	return nil
+}
+
+func TestSimulationClosesTimedOutBot(t *testing.T) {
+	stuck := &stuckBot{interrupt: make(chan struct{}), closed: make(chan bool, 1)}
+	first := newTestBot(func(turns []Turn) *Turn { return &turns[0] })
+	sim := NewSeededSimulator(0, 1, logrus.StandardLogger(), first, func(team int, board *Board, logger *logrus.Logger) TurnSelector {
+		return stuck
+	})
+	sim.TurnTimeout = 20 * time.Millisecond
+	sim.Run()
+	assert.Equal(t, ReasonForfeit, sim.Reason)
+	select {
+	case finished := <-stuck.closed:
+		assert.True(t, finished)
+	case <-time.After(time.Second):
+		t.Fatal("the bot was never closed")
+	}
+}
+
+func TestSimulationTrappedFirst(t *testing.T) {
+	// Team 1's workers are walled in by domes before either team has moved
+	board, _, err := ParseNotation("00400/44400/00000/00000/00000 1.1:a1,1.2:b1,2.1:e5,2.2:d5 1")
+	assert.NoError(t, err)
+	first := newTestBot(func(turns []Turn) *Turn { return &turns[0] })
+	sim := NewPositionSimulator(0, 1, board, logrus.StandardLogger(), first, first)
+	sim.Run()
+	assert.Equal(t, 2, sim.Board.Victor)
+	assert.Equal(t, ReasonTrapped, sim.Reason)
+	assert.Equal(t, []int{2, 1}, sim.Places())
+}
+
+func TestSimulationPlaces(t *testing.T) {
+	// Team 1 climbed while team 3 was still playing, after teams 2 and 4 were knocked out
+	sim := &Simulation{Teams: make([]TurnSelector, 4), Board: &Board{Victor: 1}, Eliminated: []int{2, 4}}
+	assert.Equal(t, []int{1, 4, 2, 3}, sim.Places())
+	sim = &Simulation{Teams: make([]TurnSelector, 4), Board: &Board{Victor: 3}}
+	assert.Equal(t, []int{2, 2, 1, 2}, sim.Places())
+
+	first := newTestBot(func(turns []Turn) *Turn { return &turns[0] })
+	crashing := newTestBot(func(turns []Turn) *Turn { panic("out of ideas") })
+	sim = NewSeededSimulator(0, 1, logrus.StandardLogger(), first, first, crashing, first)
+	sim.Run()
+	assert.Len(t, sim.Teams, 4)
+	assert.Equal(t, 3, sim.Eliminated[0])
+	places := sim.Places()
+	assert.Equal(t, 1, places[sim.Board.Victor-1])
+	assert.Equal(t, 4, places[2])
+}
+
+func TestRandomPosition(t *testing.T) {
+	board := RandomPosition(rand.New(rand.NewSource(1)), 3, 20)
+	assert.Len(t, board.Teams, 3)
+	workers, blocks := 0, 0
+	for _, tile := range board.Tiles {
+		assert.LessOrEqual(t, tile.height, 2)
+		blocks += tile.height
+		if tile.IsOccupied() {
+			workers++
+		}
+	}
+	assert.Equal(t, 6, workers)
+	assert.Greater(t, blocks, 0)
+
+	// The same seed places the workers the same way
+	assert.Equal(t, board.Notation(1), RandomPosition(rand.New(rand.NewSource(1)), 3, 20).Notation(1))
+}
@@ -0,0 +1,134 @@
+package solver
+
+import (
+	"fmt"
+	santorini "santorini/pkg"
+)
+
+// MaxSize is the largest board that can be solved. Keys must fit every height and worker in 64 bits
+const MaxSize = 4
+
+// position is a compact copy of a two team board, small enough to search millions of times
+type position struct {
+	size    int
+	heights [MaxSize * MaxSize]int8
+	workers [4]int8 // Tile index of team 1 worker 1 and 2, then team 2 worker 1 and 2
+	toMove  int8    // 0 for team 1, 1 for team 2
+}
+
+// turn is a move and build on a position
+type turn struct {
+	worker int8 // index into workers
+	move   int8
+	build  int8
+	wins   bool
+}
+
+// newPosition copies a board. Only two teams with two workers each are supported
+func newPosition(board *santorini.Board, team int) (position, error) {
+	p := position{size: board.Size}
+	if board.Size > MaxSize || board.Size < 2 {
+		return p, fmt.Errorf("board size must be between 2 and %d", MaxSize)
+	}
+	if team != 1 && team != 2 {
+		return p, fmt.Errorf("only teams 1 and 2 can be solved")
+	}
+	p.toMove = int8(team - 1)
+
+	found := 0
+	for _, tile := range board.Tiles {
+		index := tile.GetY()*board.Size + tile.GetX()
+		p.heights[index] = int8(tile.GetHeight())
+		if !tile.IsOccupied() {
+			continue
+		}
+		if tile.GetTeam() < 1 || tile.GetTeam() > 2 || tile.GetWorker() < 1 || tile.GetWorker() > 2 {
+			return p, fmt.Errorf("only two teams with two workers can be solved")
+		}
+		p.workers[(tile.GetTeam()-1)*2+tile.GetWorker()-1] = int8(index)
+		found++
+	}
+	if found != 4 {
+		return p, fmt.Errorf("expected 4 workers, found %d", found)
+	}
+	return p, nil
+}
+
+// key packs the position into a unique number. The two workers of a team are interchangeable, so they are sorted
+func (p *position) key() uint64 {
+	var key uint64
+	for i := 0; i < p.size*p.size; i++ {
+		key = key*5 + uint64(p.heights[i])
+	}
+	for team := 0; team < 2; team++ {
+		a, b := p.workers[team*2], p.workers[team*2+1]
+		if a > b {
+			a, b = b, a
+		}
+		key = key<<8 | uint64(a)<<4 | uint64(b)
+	}
+	return key<<1 | uint64(p.toMove)
+}
+
+func (p *position) occupied(index int8) bool {
+	for _, w := range p.workers {
+		if w == index {
+			return true
+		}
+	}
+	return false
+}
+
+// turns returns every legal turn for the team to move
+func (p *position) turns(adjacent [][]int8) []turn {
+	turns := make([]turn, 0, 32)
+	for w := p.toMove * 2; w < p.toMove*2+2; w++ {
+		from := p.workers[w]
+		for _, to := range adjacent[from] {
+			if p.occupied(to) || p.heights[to] > 3 || p.heights[to] > p.heights[from]+1 {
+				continue
+			}
+			if p.heights[to] == 3 {
+				turns = append(turns, turn{worker: w, move: to, build: to, wins: true})
+				continue
+			}
+			for _, build := range adjacent[to] {
+				// The worker has left its tile, so it may build there
+				if (build != from && p.occupied(build)) || p.heights[build] > 3 {
+					continue
+				}
+				turns = append(turns, turn{worker: w, move: to, build: build})
+			}
+		}
+	}
+	return turns
+}
+
+// play returns the position after the turn, with the other team to move
+func (p position) play(t turn) position {
+	p.workers[t.worker] = t.move
+	if !t.wins {
+		p.heights[t.build]++
+	}
+	p.toMove = 1 - p.toMove
+	return p
+}
+
+// adjacency lists the neighbors of every tile on a board of the given size
+func adjacency(size int) [][]int8 {
+	adjacent := make([][]int8, size*size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if (dx == 0 && dy == 0) || nx < 0 || ny < 0 || nx >= size || ny >= size {
+						continue
+					}
+					adjacent[y*size+x] = append(adjacent[y*size+x], int8(ny*size+nx))
+				}
+			}
+		}
+	}
+	return adjacent
+}
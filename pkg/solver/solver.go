@@ -0,0 +1,149 @@
+/* Package solver proves small two team positions with a memoized depth first search and
+ * stores the results in a tablebase that bots can probe.
+ *
+ * Santorini cannot end in a draw, every turn either wins or builds and a board can only
+ * hold so many blocks. So every position is a win or a loss for the team to move, the
+ * solver only reports Unknown when it ran out of depth or nodes before proving it.
+ */
+package solver
+
+import (
+	"fmt"
+	santorini "santorini/pkg"
+)
+
+// Result for the team to move
+type Result int8
+
+const (
+	Unknown Result = iota
+	Win
+	Loss
+)
+
+func (r Result) String() string {
+	switch r {
+	case Win:
+		return "win"
+	case Loss:
+		return "loss"
+	}
+	return "unknown"
+}
+
+// Entry is a proven position
+type Entry struct {
+	Result Result
+	// Turns until the game ends along the proven line. The winner takes the shortest
+	// win it found and the loser delays as long as it can
+	Distance int
+}
+
+func (e Entry) String() string {
+	if e.Result == Unknown {
+		return e.Result.String()
+	}
+	return fmt.Sprintf("%s in %d", e.Result, e.Distance)
+}
+
+// Solver searches positions, adding everything it proves to its tablebase
+type Solver struct {
+	Table    *Tablebase
+	MaxNodes int // Give up once this many positions have been searched, 0 for no limit
+	Nodes    int // Positions searched so far
+
+	adjacent [][]int8
+	searched map[uint64]int // Depth unproven positions were already searched to
+}
+
+func NewSolver(table *Tablebase) *Solver {
+	return &Solver{
+		Table:    table,
+		adjacent: adjacency(table.Size),
+		searched: make(map[uint64]int),
+	}
+}
+
+// Solve searches the position with increasing depth until it is proven or maxDepth turns have been searched
+func (s *Solver) Solve(board *santorini.Board, team int, maxDepth int) (Entry, error) {
+	if board.Size != s.Table.Size {
+		return Entry{}, fmt.Errorf("tablebase is for size %d boards, not %d", s.Table.Size, board.Size)
+	}
+	p, err := newPosition(board, team)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	for depth := 1; depth <= maxDepth; depth++ {
+		if entry := s.solve(p, depth); entry.Result != Unknown {
+			return entry, nil
+		}
+		if s.exhausted() {
+			break
+		}
+	}
+	return Entry{}, nil
+}
+
+// solve proves the position within depth turns, or returns Unknown
+func (s *Solver) solve(p position, depth int) Entry {
+	key := p.key()
+	if entry, ok := s.Table.entries[key]; ok {
+		return entry
+	}
+	if searched, ok := s.searched[key]; (ok && searched >= depth) || depth == 0 {
+		return Entry{}
+	}
+	if s.exhausted() {
+		return Entry{}
+	}
+	s.Nodes++
+
+	turns := p.turns(s.adjacent)
+	for _, t := range turns {
+		if t.wins {
+			return s.store(key, Entry{Result: Win, Distance: 1})
+		}
+	}
+
+	// A team with no turns has lost
+	if len(turns) == 0 {
+		return s.store(key, Entry{Result: Loss, Distance: 0})
+	}
+
+	proven := true
+	longestLoss := 0
+	for _, t := range turns {
+		child := s.solve(p.play(t), depth-1)
+		switch child.Result {
+		case Loss:
+			// The other team cannot escape, this turn wins
+			return s.store(key, Entry{Result: Win, Distance: child.Distance + 1})
+		case Win:
+			if child.Distance+1 > longestLoss {
+				longestLoss = child.Distance + 1
+			}
+		default:
+			proven = false
+		}
+	}
+
+	if proven {
+		return s.store(key, Entry{Result: Loss, Distance: longestLoss})
+	}
+	// A search cut short by the node limit did not cover the whole depth
+	if !s.exhausted() {
+		s.searched[key] = depth
+	}
+	return Entry{}
+}
+
+func (s *Solver) exhausted() bool {
+	return s.MaxNodes > 0 && s.Nodes >= s.MaxNodes
+}
+
+func (s *Solver) store(key uint64, entry Entry) Entry {
+	s.Table.entries[key] = entry
+	delete(s.searched, key)
+	return entry
+}
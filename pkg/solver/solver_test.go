@@ -0,0 +1,82 @@
+package solver
+
+import (
+	"math/rand"
+	"path/filepath"
+	santorini "santorini/pkg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parse(t *testing.T, notation string) (*santorini.Board, int) {
+	board, team, err := santorini.ParseNotation(notation)
+	assert.NoError(t, err)
+	return board, team
+}
+
+func TestSolveImmediateWin(t *testing.T) {
+	board, team := parse(t, "230/000/000 1.1:a1,1.2:c3,2.1:a3,2.2:c2 1")
+	entry, err := NewSolver(NewTablebase(3)).Solve(board, team, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, Entry{Result: Win, Distance: 1}, entry)
+}
+
+func TestSolveTrapped(t *testing.T) {
+	board, team := parse(t, "040/444/000 1.1:a1,1.2:c1,2.1:a3,2.2:c3 1")
+	entry, err := NewSolver(NewTablebase(3)).Solve(board, team, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, Entry{Result: Loss, Distance: 0}, entry)
+}
+
+func TestSolveUnsupported(t *testing.T) {
+	_, err := NewSolver(NewTablebase(5)).Solve(santorini.DefaultPosition(2), 1, 1)
+	assert.Error(t, err)
+	_, err = NewSolver(NewTablebase(3)).Solve(santorini.DefaultPosition(2), 1, 1)
+	assert.Error(t, err)
+}
+
+// Every proven win must have a turn leading to a proven loss one turn shorter
+func TestSolveConsistent(t *testing.T) {
+	tb := NewTablebase(3)
+	solver := NewSolver(tb)
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		board := RandomPosition(rng, 3)
+		entry, err := solver.Solve(board, 1, 8)
+		assert.NoError(t, err)
+		if entry.Result == Unknown {
+			continue
+		}
+
+		turn, best, ok := tb.BestTurn(board, 1)
+		assert.True(t, ok)
+		assert.Equal(t, entry.Result, best.Result)
+		if entry.Result == Win {
+			assert.LessOrEqual(t, best.Distance, entry.Distance)
+			if best.Distance > 1 {
+				child := board.Clone()
+				child.PlayTurn(*turn)
+				reply, found := tb.Probe(child, 2)
+				assert.True(t, found)
+				assert.Equal(t, Entry{Result: Loss, Distance: best.Distance - 1}, reply)
+			}
+		}
+	}
+	assert.NotZero(t, tb.Len())
+}
+
+func TestTablebaseSaveLoad(t *testing.T) {
+	tb := NewTablebase(3)
+	solver := NewSolver(tb)
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 20; i++ {
+		solver.Solve(RandomPosition(rng, 3), 1, 6)
+	}
+
+	path := filepath.Join(t.TempDir(), "3x3.tb")
+	assert.NoError(t, tb.Save(path))
+	loaded, err := LoadTablebase(path)
+	assert.NoError(t, err)
+	assert.Equal(t, tb, loaded)
+}
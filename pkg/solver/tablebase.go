@@ -0,0 +1,200 @@
+package solver
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	santorini "santorini/pkg"
+	"sort"
+	"strings"
+)
+
+// tablebaseMagic starts every tablebase file
+const tablebaseMagic = "STB1"
+
+// Tablebase holds proven positions for one board size
+type Tablebase struct {
+	Size    int
+	entries map[uint64]Entry
+}
+
+func NewTablebase(size int) *Tablebase {
+	return &Tablebase{
+		Size:    size,
+		entries: make(map[uint64]Entry),
+	}
+}
+
+// Len returns the number of proven positions
+func (tb *Tablebase) Len() int {
+	return len(tb.entries)
+}
+
+// Probe looks up the position for the team to move
+func (tb *Tablebase) Probe(board *santorini.Board, team int) (Entry, bool) {
+	if board.Size != tb.Size {
+		return Entry{}, false
+	}
+	p, err := newPosition(board, team)
+	if err != nil {
+		return Entry{}, false
+	}
+	entry, ok := tb.entries[p.key()]
+	return entry, ok
+}
+
+// BestTurn returns the turn that wins fastest, or loses slowest, along with the result for the team.
+// ok is false unless a winning turn was found or every turn could be probed
+func (tb *Tablebase) BestTurn(board *santorini.Board, team int) (*santorini.Turn, Entry, bool) {
+	turns := board.GetValidTurns(team)
+	if len(turns) == 0 {
+		return nil, Entry{Result: Loss}, true
+	}
+
+	var best *santorini.Turn
+	var entry Entry
+	missing := false
+	for i, turn := range turns {
+		if turn.IsVictory() {
+			return &turns[i], Entry{Result: Win, Distance: 1}, true
+		}
+		child := board.Clone()
+		child.PlayTurn(turn)
+		result, found := tb.Probe(child, 3-team)
+		switch {
+		case !found:
+			missing = true
+		case result.Result == Loss:
+			if entry.Result != Win || result.Distance+1 < entry.Distance {
+				best, entry = &turns[i], Entry{Result: Win, Distance: result.Distance + 1}
+			}
+		case entry.Result != Win && result.Distance+1 > entry.Distance:
+			best, entry = &turns[i], Entry{Result: Loss, Distance: result.Distance + 1}
+		}
+	}
+	if entry.Result == Win || (!missing && best != nil) {
+		return best, entry, true
+	}
+	return nil, Entry{}, false
+}
+
+// Save writes the tablebase as sorted, delta encoded keys followed by the result and distance
+func (tb *Tablebase) Save(path string) error {
+	keys := make([]uint64, 0, len(tb.entries))
+	for key := range tb.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	buf := make([]byte, binary.MaxVarintLen64)
+	w.WriteString(tablebaseMagic)
+	w.WriteByte(byte(tb.Size))
+	w.Write(buf[:binary.PutUvarint(buf, uint64(len(keys)))])
+
+	var last uint64
+	for _, key := range keys {
+		entry := tb.entries[key]
+		w.Write(buf[:binary.PutUvarint(buf, key-last)])
+		w.WriteByte(byte(entry.Result))
+		w.Write(buf[:binary.PutUvarint(buf, uint64(entry.Distance))])
+		last = key
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadTablebase reads a tablebase written by Save
+func LoadTablebase(path string) (*Tablebase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+
+	magic := make([]byte, len(tablebaseMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != tablebaseMagic {
+		return nil, errors.New("not a tablebase file")
+	}
+	size, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if size < 2 || size > MaxSize {
+		return nil, fmt.Errorf("invalid board size %d", size)
+	}
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	tb := NewTablebase(int(size))
+	var key uint64
+	for i := uint64(0); i < count; i++ {
+		delta, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		result, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		distance, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		key += delta
+		tb.entries[key] = Entry{Result: Result(result), Distance: int(distance)}
+	}
+	return tb, nil
+}
+
+// RandomPosition returns a board with random heights and four randomly placed workers.
+// Domes and tall buildings are common, so the positions look like the end of a game
+func RandomPosition(rng *rand.Rand, size int) *santorini.Board {
+	for {
+		notation := ""
+		for y := 0; y < size; y++ {
+			if y > 0 {
+				notation += "/"
+			}
+			for x := 0; x < size; x++ {
+				notation += fmt.Sprint(rng.Intn(5))
+			}
+		}
+
+		// Workers stand on distinct tiles no higher than level 2
+		order := rng.Perm(size * size)
+		workers := make([]string, 0, 4)
+		for _, index := range order {
+			if len(workers) == 4 {
+				break
+			}
+			x, y := index%size, index/size
+			if notation[y*(size+1)+x] > '2' {
+				continue
+			}
+			workers = append(workers, fmt.Sprintf("%d.%d:%s", len(workers)/2+1, len(workers)%2+1, santorini.SquareName(x, y)))
+		}
+		if len(workers) < 4 {
+			continue
+		}
+
+		board, _, err := santorini.ParseNotation(notation + " " + strings.Join(workers, ",") + " 1")
+		if err == nil {
+			return board
+		}
+	}
+}
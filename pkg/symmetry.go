@@ -0,0 +1,67 @@
+package santorini
+
+// Symmetry is one of the eight rotations and reflections of a square board.
+// Bit 4 swaps x and y, then bit 1 mirrors x and bit 2 mirrors y
+type Symmetry int
+
+// Symmetries is the number of symmetries of a square board, the identity is 0
+const Symmetries = 8
+
+// Apply moves the position x, y on a board of the given size
+func (s Symmetry) Apply(size, x, y int) (int, int) {
+	if s&4 != 0 {
+		x, y = y, x
+	}
+	if s&1 != 0 {
+		x = size - 1 - x
+	}
+	if s&2 != 0 {
+		y = size - 1 - y
+	}
+	return x, y
+}
+
+// Inverse returns the symmetry that undoes s
+func (s Symmetry) Inverse() Symmetry {
+	if s&4 == 0 {
+		return s
+	}
+	// Mirroring before swapping is the same as mirroring the other axis after it
+	return 4 | (s&1)<<1 | (s&2)>>1
+}
+
+func (s Symmetry) tile(size int, t Tile) Tile {
+	t.x, t.y = s.Apply(size, t.x, t.y)
+	return t
+}
+
+// Transform returns a copy of the board with the symmetry applied
+func (board Board) Transform(s Symmetry) *Board {
+	transformed := board.Clone()
+	for _, tile := range board.Tiles {
+		transformed.setTile(s.tile(board.Size, tile))
+	}
+	for i, turn := range transformed.Moves {
+		transformed.Moves[i] = s.Turn(board.Size, turn)
+	}
+	return transformed
+}
+
+// Turn applies the symmetry to a turn made on a board of the given size
+func (s Symmetry) Turn(size int, t Turn) Turn {
+	t.MoveTo = s.tile(size, t.MoveTo)
+	t.Build = s.tile(size, t.Build)
+	return t
+}
+
+// Canonical returns the smallest notation of the position among all of its symmetries,
+// and the symmetry that transforms the board into it
+func (board Board) Canonical(team int) (string, Symmetry) {
+	best, bestSym := board.Notation(team), Symmetry(0)
+	for s := Symmetry(1); s < Symmetries; s++ {
+		if notation := board.Transform(s).Notation(team); notation < best {
+			best, bestSym = notation, s
+		}
+	}
+	return best, bestSym
+}
@@ -0,0 +1,33 @@
+package santorini
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSymmetryInverse(t *testing.T) {
+	for s := Symmetry(0); s < Symmetries; s++ {
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				tx, ty := s.Apply(5, x, y)
+				ix, iy := s.Inverse().Apply(5, tx, ty)
+				assert.Equal(t, []int{x, y}, []int{ix, iy}, "symmetry %d", s)
+			}
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	board := DefaultPosition(2)
+	board.PlayTurn(Turn{Team: 1, Worker: 1, MoveTo: Tile{x: 1, y: 1}, Build: Tile{x: 0, y: 0}})
+
+	// Every symmetry of a position shares the same canonical notation
+	canonical, _ := board.Canonical(2)
+	for s := Symmetry(0); s < Symmetries; s++ {
+		transformed := board.Transform(s)
+		notation, sym := transformed.Canonical(2)
+		assert.Equal(t, canonical, notation)
+		assert.Equal(t, canonical, transformed.Transform(sym).Notation(2))
+	}
+}
@@ -10,8 +10,9 @@ import (
 
 // BoardWidget displays the board and updates it as needed
 type BoardWidget struct {
-	board *santorini.Board
-	pane  *tui.TUIPane
+	board      *santorini.Board
+	pane       *tui.TUIPane
+	highlights []santorini.Tile // Tiles to mark, such as a hint
 }
 
 func NewBoardWidget(board *santorini.Board, pane *tui.TUIPane) *BoardWidget {
@@ -37,11 +38,35 @@ func (b *BoardWidget) update(p *tui.TUIPane) int {
 			tileIcon = fmt.Sprintf("%s%v%s", color.GetWorkerColor(tile.GetTeam(), tile.GetWorker()), tileIcon, color.Reset)
 			p.Write(5*x+2, 3*y+1, tileIcon+tileIcon+tileIcon, false)
 			p.Write(5*x+2, 3*y+2, tileIcon+tileIcon+tileIcon, false)
+
+			left, right := " ", " "
+			if b.isHighlighted(x, y) {
+				left, right = "[", "]"
+			}
+			for row := 1; row <= 2; row++ {
+				p.Write(5*x+1, 3*y+row, left, false)
+				p.Write(5*x+5, 3*y+row, right, false)
+			}
 		}
 	}
 	return 1
 }
 
+// Highlight marks the tiles on the board, replacing the previous highlights
+func (b *BoardWidget) Highlight(tiles ...santorini.Tile) {
+	b.highlights = tiles
+	b.pane.Iterate()
+}
+
+func (b *BoardWidget) isHighlighted(x, y int) bool {
+	for _, tile := range b.highlights {
+		if tile.GetX() == x && tile.GetY() == y {
+			return true
+		}
+	}
+	return false
+}
+
 func (b *BoardWidget) Iterate() {
 	b.pane.Iterate()
 }
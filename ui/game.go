@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"io"
 	"os"
 	santorini "santorini/pkg"
 
@@ -13,6 +14,10 @@ type Game struct {
 	turnCounter int // the turn in the game it is Round = turnCounter/len(Teams)
 	Teams       []santorini.TurnSelector
 	Humans      []*Player // The human players
+	// Bot that suggests turns when a player asks for a hint, nil to turn hints off
+	Advisor santorini.BotInitializer
+	// Ask players to confirm turns that let an enemy climb to level 3
+	BlunderWarning bool
 
 	waitingForPrompt func(prompt string)
 
@@ -62,6 +67,7 @@ func NewGame(players int, bots ...santorini.BotInitializer) *Game {
 	g.widgets.Input = NewInputWidget(inputPane)
 	logger = g.widgets.Logs
 	g.widgets.Prompt.Set("Press ↵ to start game")
+	g.ponder()
 	g.t.SetOnKeyPress(func(t *tui.TUI, b []byte) {
 		if g.widgets.Input.onKeyPress(t, b) {
 			g.Step()
@@ -93,6 +99,7 @@ func (g *Game) Step() {
 	// Figure out whose turn it is next
 	if g.Board.IsOver {
 		if lastInput == "exit" {
+			g.Close()
 			os.Exit(0)
 		}
 		return
@@ -116,7 +123,9 @@ func (g *Game) Step() {
 		turn = bot.SelectTurn()
 	}
 	g.turnCounter += 1
+	g.widgets.Board.Highlight()
 	g.widgets.Logs.LogTurn(bot, *turn)
+	g.widgets.Logs.LogExplanation(bot)
 	if g.Board.PlayTurn(*turn) {
 		g.widgets.Logs.Printf("Game Over. %s wins in %d turns", bot.Name(), g.turnCounter/len(g.Teams))
 		g.widgets.Prompt.Set("Type 'exit' to quit")
@@ -126,16 +135,39 @@ func (g *Game) Step() {
 		} else {
 			g.widgets.Prompt.Set("Press ↵ to continue")
 		}
+		g.ponder()
 	}
 	g.Refresh()
 }
 
+// ponder lets every bot that can think about the next turn while the next team chooses it
+func (g *Game) ponder() {
+	next := g.turnCounter%len(g.Teams) + 1
+	for i, bot := range g.Teams {
+		if ponderer, ok := bot.(santorini.Ponderer); ok && i+1 != next {
+			ponderer.Ponder(g.Board, next)
+		}
+	}
+}
+
 func (g *Game) Refresh() {
 	g.widgets.Board.Iterate()
 	g.widgets.Prompt.Iterate()
 	g.widgets.Teams.Iterate()
 }
 
+// Close shuts down bots that hold on to resources, such as external engines
+func (g *Game) Close() {
+	for _, bot := range g.Teams {
+		if ponderer, ok := bot.(santorini.Ponderer); ok {
+			ponderer.StopPondering()
+		}
+		if closer, ok := bot.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
 func (g *Game) Run() {
 	g.t.Run(os.Stdout, os.Stderr)
 }
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	santorini "santorini/pkg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// hint asks the advisor for the team's best turn, logs it and highlights it on the board
+func (g *Game) hint(team int) {
+	if g.Advisor == nil {
+		g.widgets.Logs.Printf("Hints are turned off")
+		return
+	}
+	// The advisor plays on a copy so it can never touch the live board
+	bot := g.Advisor(team, g.Board.Clone(), logrus.StandardLogger())
+	if closer, ok := bot.(io.Closer); ok {
+		defer closer.Close()
+	}
+	turn := bot.SelectTurn()
+	if turn == nil {
+		g.widgets.Logs.Printf("Hint: %s has no moves", bot.Name())
+		return
+	}
+
+	from := g.Board.GetWorkerTile(team, turn.Worker)
+	msg := fmt.Sprintf("Hint from %s: move worker %d from %d,%d to %d,%d", bot.Name(), turn.Worker, from.GetX(), from.GetY(), turn.MoveTo.GetX(), turn.MoveTo.GetY())
+	highlights := []santorini.Tile{from, turn.MoveTo}
+	if !turn.IsVictory() {
+		msg += fmt.Sprintf(" and build %d,%d", turn.Build.GetX(), turn.Build.GetY())
+		highlights = append(highlights, turn.Build)
+	}
+	if explainer, ok := bot.(santorini.Explainer); ok {
+		if explanation := explainer.Explain(1); explanation != nil && len(explanation.Candidates) > 0 {
+			msg += fmt.Sprintf(" (score %.2f)", explanation.Candidates[0].Score)
+		}
+	}
+	g.widgets.Logs.Printf(msg)
+	g.widgets.Board.Highlight(highlights...)
+}
+
+// allowsWin returns the enemy teams that could climb to level 3 right after the turn
+func (g *Game) allowsWin(turn santorini.Turn) []int {
+	if turn.IsVictory() {
+		return nil
+	}
+	board := g.Board.Clone()
+	board.PlayTurn(turn)
+	var teams []int
+	for team := 1; team <= len(g.Teams); team++ {
+		if team == turn.Team {
+			continue
+		}
+		for _, enemyTurn := range board.GetValidTurns(team) {
+			if enemyTurn.IsVictory() {
+				teams = append(teams, team)
+				break
+			}
+		}
+	}
+	return teams
+}
@@ -53,6 +53,19 @@ func (l *LogWidget) LogTurn(bot santorini.TurnSelector, turn santorini.Turn) {
 	l.Printf(msg)
 }
 
+// Log why a bot chose its turn, if it can tell
+func (l *LogWidget) LogExplanation(bot santorini.TurnSelector) {
+	explainer, ok := bot.(santorini.Explainer)
+	if !ok {
+		return
+	}
+	if explanation := explainer.Explain(3); explanation != nil {
+		for _, ln := range strings.Split(explanation.String(), "\n") {
+			l.Printf("%s", ln)
+		}
+	}
+}
+
 // Update the logs
 func (l *LogWidget) update(p *tui.TUIPane) int {
 	if l.logs == nil {
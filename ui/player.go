@@ -29,6 +29,7 @@ type Player struct {
 
 	turnStage    int // 0 - select worker, 1 select move, 2 select turn, 3 turn complete
 	selectedTurn santorini.Turn
+	confirming   bool // Waiting for the player to confirm a turn that lets an enemy win
 }
 
 func (p *Player) SetName(name string) {
@@ -48,7 +49,11 @@ func (p *Player) SetChoices(prompt string, options map[string]interface{}) {
 		return
 	}
 
-	p.game.widgets.Prompt.Set(prompt)
+	if p.game.Advisor != nil {
+		p.game.widgets.Prompt.Set(prompt + " (or type 'hint')")
+	} else {
+		p.game.widgets.Prompt.Set(prompt)
+	}
 	p.game.widgets.Logs.Printf(prompt)
 	p.awaitAnswers = make([]interface{}, 0, len(options))
 	i := 0
@@ -171,6 +176,11 @@ func (p *Player) getBuild(chosen interface{}) {
 }
 func (p *Player) resume() {
 	var chosen interface{} // whatever option was selected by the player
+	if p.game.widgets.Input.lastInput == "hint" {
+		p.game.widgets.Input.Value()
+		p.game.hint(p.team)
+		return
+	}
 	// See if we have input that we are awaiting
 	if p.awaitAnswers != nil {
 		if chosen = p.GetChoice(); chosen == nil {
@@ -194,9 +204,34 @@ func (p *Player) resume() {
 		}
 	}
 
-	// If we won right here, dont even prompt for a build
-	if p.turnStage == 2 {
+	// The player was warned the turn lets an enemy win, play it or start over
+	if p.turnStage == 2 && p.confirming {
+		p.confirming = false
+		if chosen.(bool) {
+			p.turnStage++
+		} else {
+			p.turnStage = 0
+			p.getWorker(nil)
+			p.game.Refresh()
+			return
+		}
+	} else if p.turnStage == 2 {
 		p.getBuild(chosen)
+		if p.isFinished() && p.game.BlunderWarning {
+			if teams := p.game.allowsWin(p.selectedTurn); len(teams) > 0 {
+				p.turnStage = 2
+				p.confirming = true
+				p.game.widgets.Logs.Printf("Warning: team %d can climb to level 3 after this turn", teams[0])
+				p.SetChoices("Play it anyway?", map[string]interface{}{
+					"Yes, play it":       true,
+					"No, choose another": false,
+				})
+				p.game.Refresh()
+				return
+			}
+		}
+	}
+	if p.turnStage == 2 || p.isFinished() {
 		p.game.Step()
 	}
 
@@ -213,6 +248,7 @@ func (p *Player) SelectTurn() *santorini.Turn {
 	p.turnStage = 0
 	p.awaitAnswers = nil
 	p.hijacked = false
+	p.confirming = false
 	return &p.selectedTurn
 }
 